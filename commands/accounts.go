@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/IronFE/iron.cli/commands/completion"
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/apex/log"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+type accountsOptions struct {
+	authProfile string
+	cached      bool
+	filter      string
+	format      string
+}
+
+func NewAccountsCommand() *cobra.Command {
+	options := accountsOptions{}
+	cmd := &cobra.Command{
+		Use:   "accounts [filter]",
+		Short: "Lists the accessible AWS accounts and their roles",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				options.filter = args[0]
+			}
+			return accounts(options)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.authProfile, "profile", "p", "", "The AWS credentials profile to use")
+	cmd.Flags().StringVarP(&options.format, "format", "f", "table", "Sets the format of the output. Allowed values are `table` and `json`")
+	cmd.Flags().BoolVar(&options.cached, "cached", false, "Lists the accounts of the last invocation instead of querying AWS")
+	completion.RegisterAwsFlags(cmd)
+
+	return cmd
+}
+
+func accounts(options accountsOptions) error {
+	printFunc, found := map[string]func(accounts []aws.AccountInfo) error{
+		"table": printAccountTable,
+		"json":  printAccountJson,
+	}[options.format]
+	if !found {
+		return fmt.Errorf("unknown format option: %s", options.format)
+	}
+
+	profile, err := selectProfile(options.authProfile)
+	if err != nil {
+		return err
+	}
+
+	cache := aws.NewAccountCache()
+	var accountInfos []aws.AccountInfo
+	if options.cached {
+		if accountInfos, _, err = cache.Get(profile.Name); err != nil {
+			return err
+		}
+	} else {
+		awsAbstraction, err := aws.NewAws(profile.Name)
+		if err != nil {
+			return err
+		}
+
+		if accountInfos, err = awsAbstraction.ListAccounts(); err != nil {
+			return err
+		}
+		if err = cache.Put(profile.Name, accountInfos); err != nil {
+			log.WithError(err).Warn("failed to cache accounts")
+		}
+	}
+
+	slices.SortFunc(accountInfos, func(a, b aws.AccountInfo) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return printFunc(filterAccounts(accountInfos, options.filter))
+}
+
+// filterAccounts keeps the accounts whose name, id or email contains the filter ignoring the case
+func filterAccounts(accounts []aws.AccountInfo, filter string) []aws.AccountInfo {
+	filter = strings.ToLower(filter)
+	return lo.Filter(accounts, func(account aws.AccountInfo, _ int) bool {
+		return strings.Contains(strings.ToLower(account.Name), filter) ||
+			strings.Contains(account.Id, filter) ||
+			strings.Contains(strings.ToLower(account.Email), filter)
+	})
+}
+
+func printAccountTable(accounts []aws.AccountInfo) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "NAME\tID\tEMAIL\tROLES")
+	for _, account := range accounts {
+		roles := strings.Join(account.Roles, ",")
+		if roles == "" {
+			roles = "-"
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", account.Name, account.Id, account.Email, roles)
+	}
+	return writer.Flush()
+}
+
+func printAccountJson(accounts []aws.AccountInfo) error {
+	data, err := json.MarshalIndent(accounts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal accounts: %w", err)
+	}
+	_, _ = fmt.Println(string(data))
+	return nil
+}
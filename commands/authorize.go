@@ -2,11 +2,20 @@ package commands
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/IronFE/iron.cli/commands/completion"
 	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 )
 
@@ -17,9 +26,19 @@ type authorizeOptions struct {
 	credsOnly    bool
 	interactive  bool
 	role         string
+	roleChain    string
 	noRoleAssume bool
+	session      aws.SessionOptions
+	serve        bool
+	listen       string
 }
 
+// environment variables replaced by the credentials of the command
+var overriddenEnvs = append([]string{
+	"AWS_CONTAINER_CREDENTIALS_FULL_URI",
+	"AWS_CONTAINER_AUTHORIZATION_TOKEN",
+}, aws.ShadowingEnvs...)
+
 func NewAuthorizeCommand() *cobra.Command {
 	options := authorizeOptions{}
 	cmd := &cobra.Command{
@@ -36,8 +55,15 @@ func NewAuthorizeCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&options.accountName, "account", "a", "", "sets the account name")
 	cmd.Flags().StringVarP(&options.role, "role", "r", "", "sets the role to assume")
 	cmd.Flags().BoolVarP(&options.noRoleAssume, "no-assume", "n", false, "Prevents any role assume and work directly with the user")
+	cmd.Flags().StringVar(&options.roleChain, "role-chain", "", "Assumes the roles one after another, given as name of a role chain of the profile or as `account:role` list")
+	cmd.MarkFlagsMutuallyExclusive("role", "role-chain", "no-assume")
+	ApplySessionOptions(cmd, &options.session)
 	cmd.Flags().BoolVarP(&options.credsOnly, "creds", "c", false, "Prints the credentials instead of executing a command")
 	cmd.Flags().BoolVarP(&options.interactive, "interactive", "i", false, "Starts an interactive process")
+	cmd.Flags().BoolVar(&options.serve, "serve", false, "Serves auto-renewing credentials on a local endpoint for the AWS SDK container credentials provider. Without a command, the export statements are printed and the endpoint runs until it is stopped")
+	cmd.Flags().StringVar(&options.listen, "listen", "127.0.0.1:0", "Address the credentials are served on with --serve. Port 0 selects a free port")
+	cmd.MarkFlagsMutuallyExclusive("creds", "serve")
+	completion.RegisterAwsFlags(cmd)
 
 	return cmd
 }
@@ -45,23 +71,30 @@ func NewAuthorizeCommand() *cobra.Command {
 func authorize(options authorizeOptions) error {
 	var err error
 	var awsAbstraction aws.IAws
-	awsAbstraction, err = aws.NewAws(options.authProfile)
+	awsAbstraction, err = aws.NewAwsWithSession(options.authProfile, options.session)
 	if err != nil {
 		return err
 	}
 
-	var credentials *aws.AwsAccountAccess
-
-	if options.noRoleAssume {
-		credentials, err = awsAbstraction.SessionToken(30 * time.Minute)
-		if err != nil {
-			return fmt.Errorf("failed to get session token for current user: %w", err)
-		}
-	} else {
-		credentials, err = awsAbstraction.AssumeRole(options.role, options.accountName)
-		if err != nil {
-			return fmt.Errorf("failed to assume role %q in %q: %w", options.role, options.accountName, err)
+	provide := func() (*aws.AwsAccountAccess, error) {
+		if options.noRoleAssume {
+			credentials, err := awsAbstraction.SessionToken(awsAbstraction.Session().DurationOr(30 * time.Minute))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get session token for current user: %w", err)
+			}
+			return credentials, nil
 		}
+		return assumeRole(awsAbstraction, options.authProfile, options.role, options.roleChain, options.accountName)
+	}
+
+	// renewing would ask for an MFA code while the credentials are served
+	if options.serve && options.noRoleAssume && awsAbstraction.SessionTokenRequiresMfa() {
+		return errors.Errorf("--serve can not renew session tokens which require MFA; assume a role instead of --no-assume")
+	}
+
+	credentials, err := provide()
+	if err != nil {
+		return err
 	}
 
 	if options.credsOnly {
@@ -69,18 +102,43 @@ func authorize(options authorizeOptions) error {
 		return nil
 	}
 
-	cmd := exec.Command(options.args[0], options.args[1:]...)
+	credentialEnvs := map[string]string{
+		"AWS_ACCESS_KEY_ID":     credentials.AccessKeyId,
+		"AWS_SECRET_ACCESS_KEY": credentials.SecretKey,
+		"AWS_SESSION_TOKEN":     credentials.SessionToken,
+	}
 
-	envs := os.Environ()
+	if options.serve {
+		server, err := aws.ListenCredentialServer(options.listen, credentials, provide)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = server.Close()
+		}()
 
-	allEnvs := make([]string, len(envs)+3)
-	copy(allEnvs, envs)
+		if len(options.args) == 0 {
+			return serveCredentials(server, options.listen)
+		}
+		credentialEnvs = server.Env()
+	}
 
-	allEnvs[len(envs)] = fmt.Sprintf("%s=%s", "AWS_ACCESS_KEY_ID", credentials.AccessKeyId)
-	allEnvs[len(envs)+1] = fmt.Sprintf("%s=%s", "AWS_SECRET_ACCESS_KEY", credentials.SecretKey)
-	allEnvs[len(envs)+2] = fmt.Sprintf("%s=%s", "AWS_SESSION_TOKEN", credentials.SessionToken)
+	if len(options.args) == 0 {
+		return errors.Errorf("a command to execute is required unless --creds or --serve is given")
+	}
 
-	cmd.Env = allEnvs
+	cmd := exec.Command(options.args[0], options.args[1:]...)
+
+	// credentials inherited from the environment would take precedence over the credential server
+	envs := lo.Filter(os.Environ(), func(env string, _ int) bool {
+		name, _, _ := strings.Cut(env, "=")
+		return !slices.Contains(overriddenEnvs, name)
+	})
+	for name, value := range credentialEnvs {
+		envs = append(envs, fmt.Sprintf("%s=%s", name, value))
+	}
+
+	cmd.Env = envs
 	if options.interactive {
 		cmd.Stdin = os.Stdin
 	}
@@ -91,3 +149,45 @@ func authorize(options authorizeOptions) error {
 
 	return err
 }
+
+// serveCredentials prints the environment variables for the clients and serves the credentials until the process is stopped
+func serveCredentials(server *aws.CredentialServer, address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err == nil && !net.ParseIP(host).IsLoopback() && host != "localhost" {
+		log.Warnf("the credentials are served on %s and may be reachable from other hosts", host)
+	}
+
+	env := server.Env()
+	names := lo.Keys(env)
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Printf("export %s=%s\n", name, env[name])
+	}
+	fmt.Printf("unset %s\n", strings.Join(aws.ShadowingEnvs, " "))
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalChan)
+
+	log.Infof("serving credentials on %s; press ctrl+c to stop", server.Url())
+	<-signalChan
+	log.Info("stopping credential server")
+	return nil
+}
+
+// assumeRole assumes the role in the account or the roles of the chain if one is given
+func assumeRole(awsAbstraction aws.IAws, authProfile, role, roleChain, accountName string) (*aws.AwsAccountAccess, error) {
+	if roleChain == "" {
+		credentials, err := awsAbstraction.AssumeRole(role, accountName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assume role %q in %q: %w", role, accountName, err)
+		}
+		return credentials, nil
+	}
+
+	hops, err := aws.ResolveRoleChain(authProfile, roleChain)
+	if err != nil {
+		return nil, err
+	}
+	return aws.AssumeRoleChain(awsAbstraction, hops, accountName, false)
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/IronFE/iron.cli/terraform"
+	"github.com/spf13/cobra"
+)
+
+func NewCacheCommand() *cobra.Command {
+	var baseCommand = &cobra.Command{
+		Use:   "cache",
+		Short: "Manages the caches of providers, modules and Terraform versions",
+	}
+
+	baseCommand.AddCommand(NewCacheInfoCommand())
+	baseCommand.AddCommand(NewCacheCleanCommand())
+	return baseCommand
+}
+
+type cacheDir struct {
+	name string
+	path string
+	// depth is the folder depth of a single entry, e.g. a provider version is stored in `<host>/<namespace>/<type>/<version>`
+	depth int
+}
+
+func cacheDirs() []cacheDir {
+	return []cacheDir{
+		{name: "plugins", path: terraform.PluginCacheDir(), depth: 4},
+		{name: "modules", path: terraform.ModuleCacheDir(), depth: 1},
+		{name: "terraform", path: terraform.InstallDir(), depth: 1},
+	}
+}
+
+// usage returns the number of entries and the size of the cache in bytes
+func (c cacheDir) usage() (int, int64, error) {
+	entries := 0
+	var size int64
+	err := filepath.WalkDir(c.path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		relPath, err := filepath.Rel(c.path, path)
+		if err != nil {
+			return err
+		}
+		// incomplete installations are hidden
+		if strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() && relPath != "." && len(strings.Split(relPath, string(filepath.Separator))) == c.depth {
+			entries++
+		}
+
+		if !entry.IsDir() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return entries, size, err
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+type cacheCleanOptions struct {
+	plugins   bool
+	modules   bool
+	terraform bool
+}
+
+func NewCacheCleanCommand() *cobra.Command {
+	options := cacheCleanOptions{}
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Deletes the cached providers and modules",
+		Long:  "Deletes the cached providers and modules if no cache is selected. Installed Terraform versions are only deleted with --terraform.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cleanCache(options)
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.plugins, "plugins", false, "Deletes the cached providers")
+	cmd.Flags().BoolVar(&options.modules, "modules", false, "Deletes the cached modules")
+	cmd.Flags().BoolVar(&options.terraform, "terraform", false, "Deletes the installed Terraform versions")
+
+	return cmd
+}
+
+func cleanCache(options cacheCleanOptions) error {
+	if !options.plugins && !options.modules && !options.terraform {
+		options.plugins = true
+		options.modules = true
+	}
+	selected := map[string]bool{
+		"plugins":   options.plugins,
+		"modules":   options.modules,
+		"terraform": options.terraform,
+	}
+
+	for _, dir := range lo.Filter(cacheDirs(), func(dir cacheDir, _ int) bool { return selected[dir.name] }) {
+		_, size, err := dir.usage()
+		if err != nil {
+			return fmt.Errorf("failed to read the %s cache: %w", dir.name, err)
+		}
+		if err = os.RemoveAll(dir.path); err != nil {
+			return fmt.Errorf("failed to delete the %s cache: %w", dir.name, err)
+		}
+		fmt.Printf("deleted the %s cache (%s)\n", dir.name, formatSize(size))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCacheInfoCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "info",
+		Short: "Shows the location and size of the caches",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cacheInfo()
+		},
+	}
+
+	return cmd
+}
+
+func cacheInfo() error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "CACHE\tENTRIES\tSIZE\tPATH")
+	for _, dir := range cacheDirs() {
+		entries, size, err := dir.usage()
+		if err != nil {
+			return fmt.Errorf("failed to read the %s cache: %w", dir.name, err)
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%d\t%s\t%s\n", dir.name, entries, formatSize(size), dir.path)
+	}
+	return writer.Flush()
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		value /= unit
+		if value < unit || suffix == "GiB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package completion
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/IronFE/iron.cli/terraform"
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+// RegisterAwsFlags adds dynamic completions to the `profile`, `account`, `accounts` and `role` flags the command defines
+func RegisterAwsFlags(cmd *cobra.Command) {
+	completions := map[string]cobra.CompletionFunc{
+		"profile":  Profiles,
+		"account":  Accounts,
+		"accounts": AccountList,
+		"role":     Roles,
+	}
+	for name, completion := range completions {
+		if cmd.Flags().Lookup(name) != nil {
+			_ = cmd.RegisterFlagCompletionFunc(name, completion)
+		}
+	}
+}
+
+// Profiles completes the names of the configured profiles
+func Profiles(_ *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	profiles, err := config.NewProfileProvider().Profiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return lo.Map(profiles, func(profile config.Profile, _ int) cobra.Completion {
+		return profile.Name
+	}), cobra.ShellCompDirectiveNoFileComp
+}
+
+// Accounts completes the account aliases of the profile and the account names cached by `iron accounts`
+func Accounts(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var names []cobra.Completion
+	if profile, err := selectedProfile(cmd); err == nil {
+		names = lo.Keys(profile.Accounts)
+	}
+	for _, account := range cachedAccounts(cmd) {
+		names = append(names, account.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// AccountList completes the last element of a comma separated list of accounts and account groups
+func AccountList(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	candidates, directive := Accounts(cmd, args, toComplete)
+	if groups, err := config.NewProfileProvider().AccountGroups(); err == nil {
+		candidates = append(candidates, lo.Keys(groups)...)
+	}
+
+	prefix := ""
+	if index := strings.LastIndex(toComplete, ","); index >= 0 {
+		prefix = toComplete[:index+1]
+	}
+	return lo.Map(candidates, func(candidate cobra.Completion, _ int) cobra.Completion {
+		return prefix + candidate
+	}), directive | cobra.ShellCompDirectiveNoSpace
+}
+
+// Roles completes the roles of the selected account or of all cached accounts
+func Roles(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	account, _ := cmd.Flags().GetString("account")
+	if profile, err := selectedProfile(cmd); err == nil {
+		if alias, found := profile.Accounts[account]; found && alias.Name != "" {
+			account = alias.Name
+		}
+	}
+
+	var roles []string
+	for _, info := range cachedAccounts(cmd) {
+		if account == "" || strings.EqualFold(info.Name, account) {
+			roles = append(roles, info.Roles...)
+		}
+	}
+	return lo.Uniq(roles), cobra.ShellCompDirectiveNoFileComp
+}
+
+// Variants completes the variants of the deployment folder given as first argument
+func Variants(_ *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	variants, err := terraform.Variants(filepath.Clean(dir))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return variants, cobra.ShellCompDirectiveNoFileComp
+}
+
+// DeploymentDir completes the deployment folder as first argument
+func DeploymentDir(_ *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+// Instances completes the EC2 instance names cached by previous ssm sessions in the selected account
+func Instances(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	account, _ := cmd.Flags().GetString("account")
+	names, _, err := aws.NewInstanceCache().Get(profileName(cmd), account)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func cachedAccounts(cmd *cobra.Command) []aws.AccountInfo {
+	accounts, _, err := aws.NewAccountCache().Get(profileName(cmd))
+	if err != nil {
+		return nil
+	}
+	return accounts
+}
+
+// profileName returns the name of the profile given by flag or of the default profile
+func profileName(cmd *cobra.Command) string {
+	profile, err := selectedProfile(cmd)
+	if err != nil {
+		return ""
+	}
+	return profile.Name
+}
+
+func selectedProfile(cmd *cobra.Command) (config.Profile, error) {
+	if name, _ := cmd.Flags().GetString("profile"); name != "" {
+		return config.NewProfileProvider().Profile(name)
+	}
+	return config.NewProfileProvider().DefaultProfile()
+}
@@ -0,0 +1,14 @@
+package creds
+
+import "github.com/spf13/cobra"
+
+func NewCredsCommand() *cobra.Command {
+	var baseCommand = &cobra.Command{
+		Use:   "creds",
+		Short: "Manages the cached credentials of assumed roles",
+	}
+
+	baseCommand.AddCommand(NewCredsListCommand())
+	baseCommand.AddCommand(NewCredsClearCommand())
+	return baseCommand
+}
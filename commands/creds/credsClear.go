@@ -0,0 +1,57 @@
+package creds
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/IronFE/iron.cli/commands/completion"
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/spf13/cobra"
+)
+
+type credsClearOptions struct {
+	authProfile string
+	accountName string
+	expiredOnly bool
+}
+
+func NewCredsClearCommand() *cobra.Command {
+	options := credsClearOptions{}
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Deletes cached credentials",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clearCredentials(options)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.authProfile, "profile", "p", "", "Only deletes the credentials of this profile")
+	cmd.Flags().StringVarP(&options.accountName, "account", "a", "", "Only deletes the credentials of this account")
+	cmd.Flags().BoolVar(&options.expiredOnly, "expired", false, "Only deletes expired credentials")
+	completion.RegisterAwsFlags(cmd)
+
+	return cmd
+}
+
+func clearCredentials(options credsClearOptions) error {
+	deleted, err := aws.NewCredentialCache().Clear(func(cached aws.CachedCredentials) bool {
+		if options.authProfile != "" && cached.Profile != options.authProfile {
+			return false
+		}
+		if options.accountName != "" && !strings.EqualFold(cached.Account, options.accountName) {
+			return false
+		}
+		if options.expiredOnly && cached.Expiration.After(time.Now()) {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("deleted %d cached credentials\n", deleted)
+	return nil
+}
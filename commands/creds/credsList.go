@@ -0,0 +1,44 @@
+package creds
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/spf13/cobra"
+)
+
+func NewCredsListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the cached credentials",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listCredentials()
+		},
+	}
+
+	return cmd
+}
+
+func listCredentials() error {
+	entries, err := aws.NewCredentialCache().List()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "PROFILE\tACCOUNT\tROLE\tACCOUNT ID\tEXPIRES")
+	for _, entry := range entries {
+		expires := entry.Expiration.Local().Format(time.DateTime)
+		if entry.Expiration.Before(time.Now()) {
+			expires += " (expired)"
+		} else {
+			expires += fmt.Sprintf(" (in %s)", time.Until(entry.Expiration).Round(time.Minute))
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", entry.Profile, entry.Account, entry.Role, entry.AccountId, expires)
+	}
+	return writer.Flush()
+}
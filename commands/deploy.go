@@ -3,7 +3,9 @@ package commands
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/IronFE/iron.cli/terraform"
 	"github.com/IronFE/iron.cli/util"
@@ -15,6 +17,8 @@ import (
 
 type deployOptions struct {
 	Confirm bool
+	All     bool
+	Plan    string
 }
 
 func NewDeployCommand() *cobra.Command {
@@ -26,11 +30,34 @@ func NewDeployCommand() *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			terraformOptions.WorkDir = args[0]
+			if options.Plan != "" {
+				if len(terraformOptions.TargetAccounts) > 0 {
+					return errors.Errorf("--plan can only be used with a single account")
+				}
+				planFile, err := filepath.Abs(options.Plan)
+				if err != nil {
+					return fmt.Errorf("invalid plan file path %q: %w", options.Plan, err)
+				}
+				options.Plan = planFile
+			}
+			if options.Confirm {
+				// the confirmation prompts of parallel accounts would be mixed up
+				terraformOptions.Parallelism = 1
+			}
+			if options.All {
+				return runStack(terraformOptions, false, func(execution terraform.ITerraformExecution) error {
+					return deploy(execution, options)
+				})
+			}
 			return deploy(terraform.NewTerraformExecution(terraformOptions), options)
 		},
 	}
 	terraformOptions = ApplyTerraformOptions(cmd)
 	cmd.Flags().BoolVarP(&options.Confirm, "confirm", "c", false, "Stops terraform after planning")
+	cmd.Flags().BoolVar(&options.All, "all", false, "Deploys all deployments below the given folder in the order of their dependencies")
+	cmd.Flags().StringVar(&options.Plan, "plan", "", "Applies a plan saved with `iron plan --out`. The deployment is refused if the account, backend key or source files differ")
+	cmd.MarkFlagsMutuallyExclusive("confirm", "plan")
+	cmd.MarkFlagsMutuallyExclusive("all", "plan")
 
 	return cmd
 }
@@ -39,7 +66,28 @@ func deploy(execution terraform.ITerraformExecution, options *deployOptions) err
 	return execution.Execute(func(tf *tfexec.Terraform, execOptions terraform.ExecutionOptions) error {
 
 		var applyFunc func() error
-		if options.Confirm {
+		if options.Plan != "" {
+			planPath := filepath.Join(tf.WorkingDir(), "plan")
+			savedMetadata, err := terraform.ImportPlan(options.Plan, planPath)
+			if err != nil {
+				return err
+			}
+
+			currentMetadata, err := terraform.NewPlanMetadata(execOptions, options.Plan)
+			if err != nil {
+				return fmt.Errorf("failed to collect plan metadata: %w", err)
+			}
+
+			if err = savedMetadata.Verify(currentMetadata); err != nil {
+				return fmt.Errorf("refusing to apply plan %s: %w", options.Plan, err)
+			}
+
+			log.Infof("applying plan %s created at %s", options.Plan, savedMetadata.CreatedAt.Format(time.RFC3339))
+			applyFunc = func() error {
+				// variables are part of the saved plan and must not be set again
+				return tf.Apply(context.Background(), tfexec.DirOrPlan(planPath))
+			}
+		} else if options.Confirm {
 			planOpts := []tfexec.PlanOption{
 				tfexec.Out("plan"),
 			}
@@ -53,7 +101,7 @@ func deploy(execution terraform.ITerraformExecution, options *deployOptions) err
 
 			if !changes {
 				log.Info("No changes in plan")
-				return nil
+				return terraform.ErrNoChanges
 			}
 
 			response, err := util.AskUser("Apply plan? (y, n)")
@@ -15,6 +15,7 @@ import (
 
 type destroyOptions struct {
 	Confirm bool
+	All     bool
 }
 
 func NewDestroyCommand() *cobra.Command {
@@ -26,11 +27,21 @@ func NewDestroyCommand() *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			terraformOptions.WorkDir = args[0]
+			if options.Confirm {
+				// the confirmation prompts of parallel accounts would be mixed up
+				terraformOptions.Parallelism = 1
+			}
+			if options.All {
+				return runStack(terraformOptions, true, func(execution terraform.ITerraformExecution) error {
+					return destroy(execution, options)
+				})
+			}
 			return destroy(terraform.NewTerraformExecution(terraformOptions), options)
 		},
 	}
 	terraformOptions = ApplyTerraformOptions(cmd)
 	cmd.Flags().BoolVarP(&options.Confirm, "confirm", "c", false, "Stops terraform after planning")
+	cmd.Flags().BoolVar(&options.All, "all", false, "Destroys all deployments below the given folder in the reverse order of their dependencies")
 	return cmd
 }
 
@@ -54,7 +65,7 @@ func destroy(execution terraform.ITerraformExecution, options *destroyOptions) e
 
 			if !changes {
 				log.Info("No changes in plan")
-				return nil
+				return terraform.ErrNoChanges
 			}
 
 			response, err := util.AskUser("Apply plan? (y, n)")
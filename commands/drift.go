@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/IronFE/iron.cli/terraform"
+	"github.com/IronFE/iron.cli/util"
+	"github.com/IronFE/iron.cli/util/git"
+	"github.com/apex/log"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type driftOptions struct {
+	discover bool
+	report   string
+}
+
+type driftStatus string
+
+const (
+	driftStatusDrifted driftStatus = "drifted"
+	driftStatusInSync  driftStatus = "in sync"
+	driftStatusError   driftStatus = "error"
+)
+
+type driftResult struct {
+	Deployment string      `json:"deployment"`
+	Directory  string      `json:"directory"`
+	Account    string      `json:"account"`
+	AccountId  string      `json:"accountId,omitempty"`
+	Status     driftStatus `json:"status"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// driftReport collects the results of parallel executions
+type driftReport struct {
+	mutex   sync.Mutex
+	Results []driftResult `json:"results"`
+}
+
+func (r *driftReport) add(result driftResult) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Results = append(r.Results, result)
+}
+
+func (r *driftReport) count() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.Results)
+}
+
+// hasError reports whether one of the results added since the report had the given count is an error
+func (r *driftReport) hasError(from int) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, result := range r.Results[from:] {
+		if result.Status == driftStatusError {
+			return true
+		}
+	}
+	return false
+}
+
+func NewDriftCommand() *cobra.Command {
+	var terraformOptions *terraform.CliOptions
+	options := &driftOptions{}
+	cmd := &cobra.Command{
+		Use:   "drift [dir...]",
+		Short: "Checks deployments for drift between their state and the real infrastructure",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dirs, err := driftDirectories(args, options.discover)
+			if err != nil {
+				return err
+			}
+			if terraformOptions.DeploymentName != "" && len(dirs) > 1 {
+				return errors.Errorf("--name can only be used with a single deployment")
+			}
+			return drift(terraformOptions, dirs, options)
+		},
+	}
+	terraformOptions = ApplyTerraformOptions(cmd)
+	cmd.ValidArgsFunction = cobra.FixedCompletions(nil, cobra.ShellCompDirectiveFilterDirs)
+	cmd.Flags().BoolVar(&options.discover, "discover", false, "Checks all deployments found below the root of the git repository")
+	cmd.Flags().StringVar(&options.report, "report", "", "Writes a JSON report of the results to the given file")
+
+	return cmd
+}
+
+func driftDirectories(args []string, discover bool) ([]string, error) {
+	if !discover {
+		if len(args) == 0 {
+			return nil, errors.Errorf("at least one deployment directory or --discover is required")
+		}
+		return args, nil
+	}
+
+	startDir := "."
+	if len(args) > 0 {
+		startDir = args[0]
+	}
+	workDir, err := util.GetWorkDirFromArg(startDir)
+	if err != nil {
+		return nil, err
+	}
+
+	gitRoot, err := git.GetRootDir(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get root of git: %w", err)
+	}
+
+	dirs, err := terraform.DiscoverDeployments(gitRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover deployments below %s: %w", gitRoot, err)
+	}
+	if len(dirs) == 0 {
+		return nil, errors.Errorf("no deployments found below %s", gitRoot)
+	}
+
+	log.Infof("found %d deployments below %s", len(dirs), gitRoot)
+	return dirs, nil
+}
+
+func drift(terraformOptions *terraform.CliOptions, dirs []string, options *driftOptions) error {
+	report := &driftReport{}
+
+	for _, dir := range dirs {
+		dirOptions := *terraformOptions
+		dirOptions.WorkDir = dir
+		checkDrift(terraform.NewTerraformExecution(&dirOptions), &dirOptions, dir, report)
+	}
+
+	printDriftReport(report.Results)
+
+	if options.report != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal drift report: %w", err)
+		}
+		if err = os.WriteFile(options.report, data, 0644); err != nil {
+			return fmt.Errorf("failed to write drift report: %w", err)
+		}
+	}
+
+	drifted, failed := 0, 0
+	for _, result := range report.Results {
+		switch result.Status {
+		case driftStatusDrifted:
+			drifted++
+		case driftStatusError:
+			failed++
+		}
+	}
+	if drifted > 0 || failed > 0 {
+		return errors.Errorf("%d checks found drift and %d checks failed", drifted, failed)
+	}
+	return nil
+}
+
+// checkDrift runs a refresh-only plan of the directory and adds the results to the report. A failed execution is always
+// reported as failed check, even if some of its accounts reported a result.
+func checkDrift(execution terraform.ITerraformExecution, terraformOptions *terraform.CliOptions, dir string, report *driftReport) {
+	reported := report.count()
+	err := execution.Execute(func(tf *tfexec.Terraform, execOptions terraform.ExecutionOptions) error {
+		planOpts := []tfexec.PlanOption{
+			tfexec.RefreshOnly(true),
+		}
+		for _, f := range execOptions.VariableFiles {
+			planOpts = append(planOpts, tfexec.VarFile(f))
+		}
+
+		result := driftResult{
+			Deployment: execOptions.DeploymentName,
+			Directory:  dir,
+			Account:    execOptions.AccountName,
+			AccountId:  execOptions.AccountId,
+		}
+
+		drifted, err := tf.Plan(context.Background(), planOpts...)
+		if err != nil {
+			result.Status = driftStatusError
+			result.Error = err.Error()
+			report.add(result)
+			return errors.Wrap(err, "failed to run terraform plan")
+		}
+
+		if !drifted {
+			result.Status = driftStatusInSync
+			report.add(result)
+			return terraform.ErrNoChanges
+		}
+
+		result.Status = driftStatusDrifted
+		report.add(result)
+		return nil
+	})
+	if err == nil || errors.Is(err, terraform.ErrNoChanges) {
+		return
+	}
+
+	// errors before terraform could run (e.g. authentication of one of several accounts) are not reported by the action
+	if !report.hasError(reported) {
+		account := terraformOptions.TargetAccount
+		if account == "" {
+			account = strings.Join(terraformOptions.TargetAccounts, ",")
+		}
+		report.add(driftResult{
+			Directory: dir,
+			Account:   account,
+			Status:    driftStatusError,
+			Error:     err.Error(),
+		})
+	}
+}
+
+func printDriftReport(results []driftResult) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "\nDEPLOYMENT\tACCOUNT\tSTATUS\tDIRECTORY")
+	for _, result := range results {
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", result.Deployment, result.Account, result.Status, result.Directory)
+	}
+	_ = writer.Flush()
+}
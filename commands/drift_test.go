@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/IronFE/iron.cli/terraform"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/pkg/errors"
+)
+
+type fakeExecution func() error
+
+func (f fakeExecution) Execute(func(tf *tfexec.Terraform, options terraform.ExecutionOptions) error) error {
+	return f()
+}
+
+func TestCheckDriftFailedAccount(t *testing.T) {
+	report := &driftReport{}
+	options := &terraform.CliOptions{TargetAccounts: []string{"pay-prod", "shop-prod"}}
+
+	// the first account is in sync, the second one fails before terraform runs
+	execution := fakeExecution(func() error {
+		report.add(driftResult{Directory: "vpc", Account: "pay-prod", Status: driftStatusInSync})
+		return errors.New("execution failed on 1 of 2 accounts")
+	})
+	checkDrift(execution, options, "vpc", report)
+
+	if !report.hasError(0) {
+		t.Fatalf("checkDrift() results = %v, want a failed check", report.Results)
+	}
+	if got := report.Results[1].Account; got != "pay-prod,shop-prod" {
+		t.Errorf("checkDrift() account = %q, want %q", got, "pay-prod,shop-prod")
+	}
+}
+
+func TestCheckDriftInSync(t *testing.T) {
+	report := &driftReport{}
+
+	execution := fakeExecution(func() error {
+		report.add(driftResult{Directory: "vpc", Account: "pay-prod", Status: driftStatusInSync})
+		return terraform.ErrNoChanges
+	})
+	checkDrift(execution, &terraform.CliOptions{TargetAccount: "pay-prod"}, "vpc", report)
+
+	if report.hasError(0) || report.count() != 1 {
+		t.Errorf("checkDrift() results = %v, want one check in sync", report.Results)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/IronFE/iron.cli/commands/completion"
 	"github.com/IronFE/iron.cli/util/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
 	"github.com/pkg/errors"
@@ -52,6 +53,7 @@ func NewEcrCredsCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&options.role, "role", "r", "AdministratorAccess", "sets the role to assume")
 	cmd.Flags().StringVar(&options.region, "region", "", "sets the region to use")
 	cmd.Flags().StringVarP(&options.format, "format", "f", "text", "Sets the format of the output. Allowed values are `plain` for pipeaple password, `json` and `text`")
+	completion.RegisterAwsFlags(cmd)
 	return cmd
 }
 
@@ -73,7 +75,7 @@ func printText(creds ecrCredentials) error {
 }
 
 func printPassword(creds ecrCredentials) error {
-	fmt.Printf(creds.Password)
+	fmt.Print(creds.Password)
 	return nil
 }
 
@@ -96,6 +98,9 @@ func getCredentials(profile, role, accountName, region string) (ecrCredentials,
 		return ecrCredentials{}, fmt.Errorf("failed to assume role: %w", err)
 	}
 
+	if region == "" {
+		region = access.Region
+	}
 	if region == "" {
 		region = awsAbstraction.Region()
 	}
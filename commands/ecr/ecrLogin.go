@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/IronFE/iron.cli/commands/completion"
 	"github.com/apex/log"
 	"github.com/spf13/cobra"
 )
@@ -35,6 +36,7 @@ func NewEcrLoginCommand() *cobra.Command {
 	cmd.Flags().StringVar(&options.region, "region", "", "sets the region to use")
 	cmd.Flags().BoolVarP(&options.usePodman, "podman", "", true, "Uses podman for logging in")
 	cmd.Flags().BoolVarP(&options.useDocker, "docker", "", false, "Uses docker for logging in")
+	completion.RegisterAwsFlags(cmd)
 
 	return cmd
 }
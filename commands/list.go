@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/IronFE/iron.cli/terraform"
+	"github.com/IronFE/iron.cli/util"
+	"github.com/IronFE/iron.cli/util/git"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+type listOptions struct {
+	dir    string
+	format string
+}
+
+func NewListCommand() *cobra.Command {
+	options := listOptions{}
+	cmd := &cobra.Command{
+		Use:   "list [dir]",
+		Short: "Lists all Terraform deployments of the git repository",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.dir = "."
+			if len(args) > 0 {
+				options.dir = args[0]
+			}
+			return list(options)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.format, "format", "f", "table", "Sets the format of the output. Allowed values are `table` and `json`")
+
+	return cmd
+}
+
+func list(options listOptions) error {
+	printFunc, found := map[string]func(root string, deployments []terraform.Deployment) error{
+		"table": printDeploymentTable,
+		"json":  printDeploymentJson,
+	}[options.format]
+	if !found {
+		return fmt.Errorf("unknown format option: %s", options.format)
+	}
+
+	workDir, err := util.GetWorkDirFromArg(options.dir)
+	if err != nil {
+		return err
+	}
+
+	root, err := git.GetRootDir(workDir)
+	if err != nil {
+		log.WithError(err).Warnf("failed to get root of git; listing deployments below %s", workDir)
+		root = workDir
+	}
+
+	dirs, err := terraform.DiscoverDeployments(root)
+	if err != nil {
+		return fmt.Errorf("failed to discover deployments below %s: %w", root, err)
+	}
+
+	deployments := make([]terraform.Deployment, 0, len(dirs))
+	for _, dir := range dirs {
+		deployment, err := terraform.DescribeDeployment(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read deployment %s: %w", dir, err)
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	return printFunc(root, deployments)
+}
+
+func printDeploymentTable(root string, deployments []terraform.Deployment) error {
+	relative := func(path string) string {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+		return path
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "NAME\tDIRECTORY\tBACKEND KEY\tVARIANTS\tCONFIG")
+	for _, deployment := range deployments {
+		sources := make([]string, 0, len(deployment.ConfigSources))
+		for _, source := range deployment.ConfigSources {
+			sources = append(sources, relative(source))
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			deployment.Name,
+			relative(deployment.Directory),
+			deployment.BackendKey,
+			strings.Join(deployment.Variants, ","),
+			strings.Join(sources, " > "))
+	}
+	return writer.Flush()
+}
+
+func printDeploymentJson(_ string, deployments []terraform.Deployment) error {
+	output, err := json.MarshalIndent(deployments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal deployments: %w", err)
+	}
+
+	fmt.Println(string(output))
+	return nil
+}
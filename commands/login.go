@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/IronFE/iron.cli/commands/completion"
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+type loginOptions struct {
+	authProfile string
+	noBrowser   bool
+	qrCode      bool
+	timeout     time.Duration
+}
+
+func NewLoginCommand() *cobra.Command {
+	options := loginOptions{}
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Logs into AWS IAM Identity Center",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return login(options)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.authProfile, "profile", "p", "", "The AWS credentials profile to use")
+	cmd.Flags().BoolVar(&options.noBrowser, "no-browser", false, "Only prints the login link and code instead of opening a browser")
+	cmd.Flags().BoolVar(&options.qrCode, "qr", false, "Prints the login link as QR code")
+	cmd.Flags().DurationVar(&options.timeout, "timeout", 5*time.Minute, "Maximum time to wait for the login to be confirmed")
+	completion.RegisterAwsFlags(cmd)
+
+	return cmd
+}
+
+func login(options loginOptions) error {
+	awsAbstraction, err := aws.NewAws(options.authProfile)
+	if err != nil {
+		return err
+	}
+
+	err = awsAbstraction.Login(aws.LoginOptions{
+		NoBrowser: options.noBrowser,
+		QrCode:    options.qrCode,
+		Timeout:   options.timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	expiration, err := awsAbstraction.LoginExpiration()
+	if err != nil || expiration.IsZero() {
+		log.Info("login successful")
+		return nil
+	}
+	log.Infof("login successful, valid until %s", expiration.Local().Format(time.DateTime))
+	return nil
+}
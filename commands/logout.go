@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"github.com/IronFE/iron.cli/commands/completion"
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+func NewLogoutCommand() *cobra.Command {
+	var authProfile string
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Revokes the login and deletes the cached tokens and credentials of a profile",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			awsAbstraction, err := aws.NewAws(authProfile)
+			if err != nil {
+				return err
+			}
+
+			if err = awsAbstraction.Logout(); err != nil {
+				return err
+			}
+			log.Info("logout successful")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&authProfile, "profile", "p", "", "The AWS credentials profile to use")
+	completion.RegisterAwsFlags(cmd)
+
+	return cmd
+}
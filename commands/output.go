@@ -1,37 +1,229 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/IronFE/iron.cli/terraform"
+	"github.com/apex/log"
 	"github.com/hashicorp/terraform-exec/tfexec"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+const maskedOutput = "(sensitive)"
+
+type outputOptions struct {
+	format        string
+	names         []string
+	showSensitive bool
+	// multiAccount is set when running on several accounts. The text formats print a header before the outputs of each
+	// account, the structured formats print a single document keyed by account.
+	multiAccount bool
+}
+
+type outputValue struct {
+	name      string
+	sensitive bool
+	value     interface{}
+}
+
+// parallel accounts print their outputs at the same time
+var outputMutex sync.Mutex
+
+var envNameInvalidChars = regexp.MustCompile("[^A-Z0-9_]")
+
 func NewOutputCommand() *cobra.Command {
 	var options *terraform.CliOptions
+	outputOpts := &outputOptions{}
 	cmd := &cobra.Command{
-		Use:   "output",
+		Use:   "output <dir> [name...]",
 		Short: "Prints the outputs of Terraform deployment",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			options.WorkDir = args[0]
-			return output(terraform.NewTerraformExecution(options))
+			outputOpts.names = args[1:]
+			outputOpts.multiAccount = len(options.TargetAccounts) > 0
+			if _, found := outputFormats[outputOpts.format]; !found {
+				return fmt.Errorf("unknown format option: %s", outputOpts.format)
+			}
+			// keep stdout clean for the outputs
+			options.TerraformOutput = os.Stderr
+			return output(terraform.NewTerraformExecution(options), outputOpts)
 		},
 	}
 	options = ApplyTerraformOptions(cmd)
+	cmd.Flags().StringVarP(&outputOpts.format, "format", "f", "text", "Sets the format of the output. Allowed values are `text`, `json`, `yaml` and `env` for `export KEY=value` lines")
+	cmd.Flags().BoolVar(&outputOpts.showSensitive, "show-sensitive", false, "Prints the values of sensitive outputs instead of masking them")
 
 	return cmd
 }
 
-func output(execution terraform.ITerraformExecution) error {
-	return execution.Execute(func(tf *tfexec.Terraform, execOptions terraform.ExecutionOptions) error {
-		_, err := tf.Output(context.Background())
+var outputFormats = map[string]func(w io.Writer, values []outputValue) error{
+	"text": printOutputText,
+	"json": printOutputJson,
+	"yaml": printOutputYaml,
+	"env":  printOutputEnv,
+}
+
+// structuredFormats print the outputs of several accounts as single document
+var structuredFormats = map[string]func(w io.Writer, result interface{}) error{
+	"json": writeJson,
+	"yaml": writeYaml,
+}
+
+func output(execution terraform.ITerraformExecution, options *outputOptions) error {
+	writeDocument, structured := structuredFormats[options.format]
+	structured = structured && options.multiAccount
+	accounts := map[string]map[string]interface{}{}
+
+	err := execution.Execute(func(tf *tfexec.Terraform, execOptions terraform.ExecutionOptions) error {
+		// terraform prints the raw outputs including sensitive values
+		tf.SetStdout(io.Discard)
+		outputs, err := tf.Output(context.Background())
 		if err != nil {
 			return errors.Wrap(err, "failed to run terraform output")
 		}
 
-		return nil
+		values, err := selectOutputs(outputs, options)
+		if err != nil {
+			return err
+		}
+
+		outputMutex.Lock()
+		defer outputMutex.Unlock()
+		if structured {
+			accounts[execOptions.AccountName] = outputsMap(values)
+			return nil
+		}
+
+		buffer := bytes.Buffer{}
+		if options.multiAccount {
+			_, _ = fmt.Fprintf(&buffer, "# %s (%s)\n", execOptions.AccountName, execOptions.AccountId)
+		}
+		if err = outputFormats[options.format](&buffer, values); err != nil {
+			return err
+		}
+		_, err = buffer.WriteTo(os.Stdout)
+		return err
 	})
+
+	// the outputs of the accounts which succeeded are printed even if others failed
+	if structured && len(accounts) > 0 {
+		if writeErr := writeDocument(os.Stdout, accounts); err == nil {
+			err = writeErr
+		}
+	}
+	return err
+}
+
+func selectOutputs(outputs map[string]tfexec.OutputMeta, options *outputOptions) ([]outputValue, error) {
+	names := options.names
+	if len(names) == 0 {
+		names = lo.Keys(outputs)
+		slices.Sort(names)
+	}
+
+	values := make([]outputValue, 0, len(names))
+	for _, name := range names {
+		meta, found := outputs[name]
+		if !found {
+			return nil, errors.Errorf("the deployment has no output %q", name)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(meta.Value, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse output %q: %w", name, err)
+		}
+		if meta.Sensitive && !options.showSensitive {
+			value = maskedOutput
+		}
+
+		values = append(values, outputValue{name: name, sensitive: meta.Sensitive, value: value})
+	}
+	return values, nil
+}
+
+func printOutputText(w io.Writer, values []outputValue) error {
+	for _, value := range values {
+		text, err := scalarText(value.value)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "%s = %s\n", value.name, text)
+	}
+	return nil
+}
+
+func printOutputJson(w io.Writer, values []outputValue) error {
+	return writeJson(w, outputsMap(values))
+}
+
+func printOutputYaml(w io.Writer, values []outputValue) error {
+	return writeYaml(w, outputsMap(values))
+}
+
+func outputsMap(values []outputValue) map[string]interface{} {
+	return lo.SliceToMap(values, func(value outputValue) (string, interface{}) {
+		return value.name, value.value
+	})
+}
+
+func writeJson(w io.Writer, result interface{}) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal outputs: %w", err)
+	}
+	_, _ = fmt.Fprintln(w, string(data))
+	return nil
+}
+
+func writeYaml(w io.Writer, result interface{}) error {
+	data, err := yaml.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outputs: %w", err)
+	}
+	_, _ = w.Write(data)
+	return nil
+}
+
+func printOutputEnv(w io.Writer, values []outputValue) error {
+	for _, value := range values {
+		if value.value == maskedOutput && value.sensitive {
+			log.Warnf("skipping sensitive output %q; use --show-sensitive to export it", value.name)
+			continue
+		}
+
+		text, err := scalarText(value.value)
+		if err != nil {
+			return err
+		}
+
+		name := envNameInvalidChars.ReplaceAllString(strings.ToUpper(value.name), "_")
+		_, _ = fmt.Fprintf(w, "export %s='%s'\n", name, strings.ReplaceAll(text, "'", `'\''`))
+	}
+	return nil
+}
+
+// scalarText returns strings as they are and all other values as JSON
+func scalarText(value interface{}) (string, error) {
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal output value: %w", err)
+	}
+	return string(data), nil
 }
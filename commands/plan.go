@@ -2,44 +2,127 @@ package commands
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 
 	"github.com/IronFE/iron.cli/terraform"
+	"github.com/apex/log"
 	"github.com/hashicorp/terraform-exec/tfexec"
 	"github.com/pkg/errors"
 
 	"github.com/spf13/cobra"
 )
 
+type planOptions struct {
+	Out    string
+	Format string
+}
+
+// parallel accounts print their summaries at the same time
+var planSummaryMutex sync.Mutex
+
 func NewPlanCommand() *cobra.Command {
 
 	var options *terraform.CliOptions
+	var planOpts = &planOptions{}
 	cmd := &cobra.Command{
 		Use:   "plan",
 		Short: "Executes Terraforms plan functionality",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			options.WorkDir = args[0]
-			return plan(terraform.NewTerraformExecution(options))
+			switch planOpts.Format {
+			case "text":
+			case "json", "markdown":
+				// keep stdout clean for the summary
+				options.TerraformOutput = os.Stderr
+			default:
+				return fmt.Errorf("unknown format option: %s", planOpts.Format)
+			}
+			if planOpts.Out != "" {
+				if len(options.TargetAccounts) > 0 {
+					return errors.Errorf("--out can only be used with a single account")
+				}
+				out, err := filepath.Abs(planOpts.Out)
+				if err != nil {
+					return fmt.Errorf("invalid plan file path %q: %w", planOpts.Out, err)
+				}
+				planOpts.Out = out
+			}
+			return plan(terraform.NewTerraformExecution(options), planOpts)
 		},
 	}
 
 	options = ApplyTerraformOptions(cmd)
+	cmd.Flags().StringVarP(&planOpts.Format, "format", "f", "text", "Sets the format of the output. Allowed values are `text` for the Terraform output, `json` and `markdown` for a summary of the changes")
+	cmd.Flags().StringVarP(&planOpts.Out, "out", "o", "", "Saves the plan and its metadata to the given file, so it can be applied with `iron deploy --plan`")
 
 	return cmd
 }
 
-func plan(execution terraform.ITerraformExecution) error {
+func plan(execution terraform.ITerraformExecution, planOpts *planOptions) error {
 	return execution.Execute(func(tf *tfexec.Terraform, options terraform.ExecutionOptions) error {
 		var tfOpts []tfexec.PlanOption
 		for _, f := range options.VariableFiles {
 			tfOpts = append(tfOpts, tfexec.VarFile(f))
 		}
 
-		ok, err := tf.Plan(context.Background(), tfOpts...)
-		if !ok {
+		planPath := filepath.Join(tf.WorkingDir(), "plan")
+		if planOpts.Out != "" || planOpts.Format != "text" {
+			tfOpts = append(tfOpts, tfexec.Out(planPath))
+		}
+
+		changes, err := tf.Plan(context.Background(), tfOpts...)
+		if err != nil {
 			return errors.Wrap(err, "failed to run terraform plan")
 		}
 
+		if planOpts.Out != "" {
+			metadata, err := terraform.NewPlanMetadata(options, planOpts.Out)
+			if err != nil {
+				return fmt.Errorf("failed to collect plan metadata: %w", err)
+			}
+			if err = terraform.ExportPlan(planPath, metadata, planOpts.Out); err != nil {
+				return err
+			}
+			log.Infof("plan saved to %s", planOpts.Out)
+		}
+
+		if planOpts.Format != "text" {
+			if err = printPlanSummary(tf, planPath, options, planOpts.Format); err != nil {
+				return err
+			}
+		}
+
+		if !changes {
+			return terraform.ErrNoChanges
+		}
+
 		return nil
 	})
 }
+
+func printPlanSummary(tf *tfexec.Terraform, planPath string, options terraform.ExecutionOptions, format string) error {
+	planJson, err := tf.ShowPlanFile(context.Background(), planPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to run terraform show")
+	}
+
+	summary := terraform.NewPlanSummary(planJson, options)
+
+	var output string
+	if format == "json" {
+		if output, err = summary.Json(); err != nil {
+			return err
+		}
+	} else {
+		output = summary.Markdown()
+	}
+
+	planSummaryMutex.Lock()
+	defer planSummaryMutex.Unlock()
+	fmt.Println(output)
+	return nil
+}
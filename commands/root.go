@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/IronFE/iron.cli/commands/cache"
+	"github.com/IronFE/iron.cli/commands/creds"
 	"github.com/IronFE/iron.cli/commands/ecr"
 	"github.com/spf13/cobra"
 )
@@ -25,11 +27,18 @@ func ExecuteRootCommand() {
 	rootCmd.AddCommand(NewDeployCommand())
 	rootCmd.AddCommand(NewDestroyCommand())
 	rootCmd.AddCommand(NewOutputCommand())
+	rootCmd.AddCommand(NewDriftCommand())
+	rootCmd.AddCommand(NewListCommand())
 	rootCmd.AddCommand(NewAuthorizeCommand())
+	rootCmd.AddCommand(NewAccountsCommand())
+	rootCmd.AddCommand(NewLoginCommand())
+	rootCmd.AddCommand(NewLogoutCommand())
+	rootCmd.AddCommand(NewWhoamiCommand())
 	rootCmd.AddCommand(NewSsmSessionCommand())
 	rootCmd.AddCommand(ecr.NewEcrCommand())
+	rootCmd.AddCommand(creds.NewCredsCommand())
+	rootCmd.AddCommand(cache.NewCacheCommand())
 
-	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	rootCmd.SilenceUsage = true
 	rootCmd.Version = version
 	rootCmd.SetVersionTemplate("{{println .Version}}")
@@ -0,0 +1,14 @@
+package commands
+
+import (
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/spf13/cobra"
+)
+
+// ApplySessionOptions adds the flags configuring the sessions of assumed roles. They override the session settings of the profile.
+func ApplySessionOptions(command *cobra.Command, session *aws.SessionOptions) {
+	command.Flags().DurationVar(&session.Duration, "session-duration", 0, "Duration of the role sessions (and MFA session tokens), e.g. `2h`. The role must allow the duration")
+	command.Flags().StringVar(&session.NameTemplate, "session-name", "", "Template of the role session name. Available fields: `{{.User}}`, `{{.GitUser}}`, `{{.Hostname}}` and `{{.Deployment}}`")
+	command.Flags().StringVar(&session.SourceIdentity, "source-identity", "", "Source identity of the role sessions. Supports the fields of the session name template")
+	command.Flags().StringToStringVar(&session.Tags, "session-tag", nil, "Session tags as `key=value` pairs")
+}
@@ -7,9 +7,11 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/IronFE/iron.cli/commands/completion"
 	"github.com/IronFE/iron.cli/util/aws"
 	"github.com/apex/log"
 	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
@@ -20,12 +22,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// instanceCacheTTL is the time after which the instance names for the shell completion are listed again
+const instanceCacheTTL = 24 * time.Hour
+
 type ssmSessionOptions struct {
 	authProfile      string
 	accountName      string
 	credsOnly        bool
 	instanceNameOrId string
 	role             string
+	roleChain        string
 	region           string
 	portForwarding   string
 }
@@ -45,8 +51,12 @@ func NewSsmSessionCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&options.authProfile, "profile", "p", "", "The AWS credentials profile to use")
 	cmd.Flags().StringVarP(&options.accountName, "account", "a", "", "sets the account name")
 	cmd.Flags().StringVarP(&options.role, "role", "r", "", "sets the role to assume")
+	cmd.Flags().StringVar(&options.roleChain, "role-chain", "", "Assumes the roles one after another, given as name of a role chain of the profile or as `account:role` list")
+	cmd.MarkFlagsMutuallyExclusive("role", "role-chain")
 	cmd.Flags().StringVar(&options.region, "region", "", "sets the region to use")
 	cmd.Flags().StringVar(&options.portForwarding, "port", "", "Allows to forward exactly one port. Use <local_port>:<remote_port>")
+	completion.RegisterAwsFlags(cmd)
+	cmd.ValidArgsFunction = completion.Instances
 
 	return cmd
 }
@@ -59,18 +69,21 @@ func startSession(options ssmSessionOptions) error {
 		return err
 	}
 
-	creds, err := awsAbstraction.AssumeRole(options.role, options.accountName)
+	creds, err := assumeRole(awsAbstraction, options.authProfile, options.role, options.roleChain, options.accountName)
 	if err != nil {
-		return fmt.Errorf("failed to assume role %q in %q: %w", options.role, options.accountName, err)
+		return err
 	}
 
 	region := options.region
+	if region == "" {
+		region = creds.Region
+	}
 	if region == "" {
 		region = awsAbstraction.Region()
 	}
 	args := []string{
 		"ssm", "start-session",
-		"--target", instanceId(options.instanceNameOrId, creds, region),
+		"--target", instanceId(options.instanceNameOrId, creds, region, options),
 	}
 	if options.portForwarding != "" {
 		args = append(args, []string{"--document-name", "AWS-StartPortForwardingSession"}...)
@@ -165,7 +178,7 @@ func startSession(options ssmSessionOptions) error {
 	return err
 }
 
-func instanceId(nameOrInstanceId string, creds *aws.AwsAccountAccess, region string) string {
+func instanceId(nameOrInstanceId string, creds *aws.AwsAccountAccess, region string, options ssmSessionOptions) string {
 	credsProvider := credentials.NewStaticCredentialsProvider(creds.AccessKeyId, creds.SecretKey, creds.SessionToken)
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region), config.WithCredentialsProvider(credsProvider))
 	if err != nil {
@@ -174,6 +187,8 @@ func instanceId(nameOrInstanceId string, creds *aws.AwsAccountAccess, region str
 	}
 
 	ec2Client := ec2.NewFromConfig(cfg)
+	refreshInstanceNames(ec2Client, options)
+
 	input := ec2.DescribeInstancesInput{
 		Filters: []types.Filter{
 			{Name: awsSdk.String("tag:Name"), Values: []string{nameOrInstanceId}},
@@ -204,3 +219,55 @@ outer:
 
 	return instanceId
 }
+
+func instanceName(instance types.Instance) string {
+	for _, tag := range instance.Tags {
+		if awsSdk.ToString(tag.Key) == "Name" {
+			return awsSdk.ToString(tag.Value)
+		}
+	}
+	return ""
+}
+
+// refreshInstanceNames caches the names of the running instances for the shell completion. Listing all instances of
+// large accounts takes a while, so it only happens if the cache is stale.
+func refreshInstanceNames(ec2Client *ec2.Client, options ssmSessionOptions) {
+	profile, err := selectProfile(options.authProfile)
+	if err != nil {
+		return
+	}
+
+	cache := aws.NewInstanceCache()
+	if _, updatedAt, err := cache.Get(profile.Name, options.accountName); err == nil && time.Since(updatedAt) < instanceCacheTTL {
+		return
+	}
+
+	input := ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			{Name: awsSdk.String("instance-state-name"), Values: []string{"running"}},
+		},
+	}
+
+	var names []string
+	paginator := ec2.NewDescribeInstancesPaginator(ec2Client, &input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			log.WithError(err).Debug("failed to list ec2 instances for the shell completion")
+			return
+		}
+
+		for _, reservation := range output.Reservations {
+			for _, instance := range reservation.Instances {
+				if name := instanceName(instance); name != "" {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+
+	slices.Sort(names)
+	if err = cache.Put(profile.Name, options.accountName, slices.Compact(names)); err != nil {
+		log.WithError(err).Debug("failed to cache instance names")
+	}
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/IronFE/iron.cli/terraform"
+	"github.com/IronFE/iron.cli/util"
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// runStack runs the command on all deployments below terraformOptions.WorkDir in the order of their dependencies.
+// With reverse, dependents run before their dependencies.
+func runStack(terraformOptions *terraform.CliOptions, reverse bool, run func(execution terraform.ITerraformExecution) error) error {
+	if terraformOptions.DeploymentName != "" {
+		return errors.Errorf("--name can not be used with --all")
+	}
+
+	root, err := util.GetWorkDirFromArg(terraformOptions.WorkDir)
+	if err != nil {
+		return err
+	}
+
+	dirs, err := terraform.DiscoverDeployments(root)
+	if err != nil {
+		return fmt.Errorf("failed to discover deployments below %s: %w", root, err)
+	}
+
+	deployments := make([]terraform.Deployment, 0, len(dirs))
+	for _, dir := range dirs {
+		deployment, err := terraform.DescribeDeployment(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read deployment %s: %w", dir, err)
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	stack, err := terraform.NewStack(deployments)
+	if err != nil {
+		return err
+	}
+
+	levels, err := stack.Order()
+	if err != nil {
+		return err
+	}
+	for i, level := range levels {
+		names := lo.Map(level, func(deployment terraform.Deployment, _ int) string { return deployment.Name })
+		log.Infof("stage %d: %s", i+1, strings.Join(names, ", "))
+	}
+
+	results := stack.Run(reverse, terraformOptions.Parallelism, func(deployment terraform.Deployment) error {
+		deploymentOptions := *terraformOptions
+		deploymentOptions.WorkDir = deployment.Directory
+
+		output := util.NewPrefixWriter(os.Stdout, fmt.Sprintf("[%s] ", deployment.Name))
+		deploymentOptions.TerraformOutput = output
+		defer func() {
+			_ = output.Flush()
+		}()
+
+		return run(terraform.NewTerraformExecution(&deploymentOptions))
+	})
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "\nDEPLOYMENT\tSTATUS\tDETAILS")
+	for _, result := range results {
+		details := ""
+		if result.Err != nil {
+			details = strings.SplitN(result.Err.Error(), "\n", 2)[0]
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\n", result.Deployment.Name, result.Status, details)
+	}
+	_ = writer.Flush()
+
+	failed := lo.CountBy(results, func(result terraform.StackResult) bool {
+		return result.Status != terraform.StackStatusSuccess
+	})
+	if failed > 0 {
+		return fmt.Errorf("%d of %d deployments did not succeed", failed, len(results))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"github.com/IronFE/iron.cli/commands/completion"
 	"github.com/IronFE/iron.cli/terraform"
 	"github.com/spf13/cobra"
 )
@@ -12,12 +13,25 @@ func ApplyTerraformOptions(command *cobra.Command) *terraform.CliOptions {
 	command.Flags().StringVarP(&optionset.TargetAccount, "account", "a", "", "Alias of the AWS Account to run the Terraform command on")
 	command.Flags().StringVarP(&optionset.RoleToAssume, "role", "r", "", "The AWS role to assume")
 	command.Flags().BoolVar(&optionset.NoRoleAssume, "no-assume", false, "Prevents any role assume and work directly with the user")
-	command.MarkFlagRequired("account")
+	command.Flags().StringVar(&optionset.RoleChain, "role-chain", "", "Assumes the roles one after another, given as name of a role chain of the profile or as `account:role` list. The account of the last hop may be empty to use the target account")
+	command.MarkFlagsMutuallyExclusive("role", "role-chain", "no-assume")
+	command.Flags().StringSliceVar(&optionset.TargetAccounts, "accounts", nil, "Comma separated list of account aliases or account groups to run the Terraform command on")
+	command.Flags().IntVar(&optionset.Parallelism, "parallelism", 4, "Maximum number of accounts the Terraform command runs on in parallel when using --accounts")
+	command.MarkFlagsOneRequired("account", "accounts")
+	command.MarkFlagsMutuallyExclusive("account", "accounts")
 	command.Flags().BoolVar(&optionset.KeepTempDir, "keep-temp", false, "Keep the temp dir created during terraform operation")
 	command.Flags().StringVarP(&optionset.DebugLevel, "debug", "d", "", "Sets the terraform log level. Valid values are: TRACE, DEBUG, INFO, WARN, ERROR. There is a bug, so keep the temp folder and look into it (https://github.com/hashicorp/terraform-exec/issues/436). See https://developer.hashicorp.com/terraform/internals/debugging")
 	command.Flags().BoolVarP(&optionset.Mfa, "mfa", "m", false, "Asks for an MFA Token")
+	command.Flags().BoolVar(&optionset.Upgrade, "upgrade", false, "Upgrades the providers and modules to the newest versions allowed by the constraints (terraform init -upgrade)")
+	command.Flags().BoolVar(&optionset.Offline, "offline", false, "Only uses installed Terraform versions instead of downloading the version required by the config")
+	command.Flags().BoolVar(&optionset.StaticCreds, "static-credentials", false, "Passes the credentials as environment variables to Terraform instead of refreshing them during long-running operations")
 	command.Flags().StringVarP(&optionset.Variant, "variant", "v", "", "Put in variant of variables to set. An appropriate .tfvars file in the `variants` folder must be present.")
 	command.Flags().StringVarP(&optionset.DeploymentName, "name", "n", "", "Sets the name of the deployment. If nothing is given, the name of folder the terraform files are in is used.")
 
+	ApplySessionOptions(command, &optionset.Session)
+	completion.RegisterAwsFlags(command)
+	_ = command.RegisterFlagCompletionFunc("variant", completion.Variants)
+	command.ValidArgsFunction = completion.DeploymentDir
+
 	return &optionset
 }
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/IronFE/iron.cli/commands/completion"
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+type whoamiOptions struct {
+	accountName string
+	authProfile string
+	role        string
+	roleChain   string
+}
+
+func NewWhoamiCommand() *cobra.Command {
+	options := whoamiOptions{}
+	cmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Prints the active profile, the login expiry and the identity used in an account",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return whoami(options)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.authProfile, "profile", "p", "", "The AWS credentials profile to use")
+	cmd.Flags().StringVarP(&options.accountName, "account", "a", "", "Resolves the identity of the role in this account")
+	cmd.Flags().StringVarP(&options.role, "role", "r", "", "sets the role to assume")
+	cmd.Flags().StringVar(&options.roleChain, "role-chain", "", "Assumes the roles one after another, given as name of a role chain of the profile or as `account:role` list")
+	cmd.MarkFlagsMutuallyExclusive("role", "role-chain")
+	completion.RegisterAwsFlags(cmd)
+
+	return cmd
+}
+
+func whoami(options whoamiOptions) error {
+	profile, err := selectProfile(options.authProfile)
+	if err != nil {
+		return err
+	}
+
+	awsAbstraction, err := aws.NewAws(profile.Name)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintf(writer, "Profile:\t%s (%s)\n", profile.Name, profile.AuthStrategy)
+
+	loginExpiration, err := awsAbstraction.LoginExpiration()
+	switch {
+	case err != nil:
+		_, _ = fmt.Fprintln(writer, "Login:\tnot logged in")
+	case !loginExpiration.IsZero():
+		_, _ = fmt.Fprintf(writer, "Login:\t%s\n", expiryText(loginExpiration))
+	}
+
+	if options.accountName == "" && options.roleChain == "" {
+		return writer.Flush()
+	}
+
+	access, err := assumeRole(awsAbstraction, profile.Name, options.role, options.roleChain, options.accountName)
+	if err != nil {
+		_ = writer.Flush()
+		return err
+	}
+
+	region := access.Region
+	if region == "" {
+		region = awsAbstraction.Region()
+	}
+	identity, err := aws.GetCallerIdentity(access, region)
+	if err != nil {
+		_ = writer.Flush()
+		return err
+	}
+
+	_, _ = fmt.Fprintf(writer, "Account:\t%s (%s)\n", lo.Ternary(options.accountName == "", "-", options.accountName), identity.Account)
+	_, _ = fmt.Fprintf(writer, "Role:\t%s\n", roleFromArn(identity.Arn))
+	_, _ = fmt.Fprintf(writer, "Caller:\t%s\n", identity.Arn)
+	_, _ = fmt.Fprintf(writer, "User id:\t%s\n", identity.UserId)
+	if !access.Expiration.IsZero() {
+		_, _ = fmt.Fprintf(writer, "Credentials:\t%s\n", expiryText(access.Expiration))
+	}
+	return writer.Flush()
+}
+
+func selectProfile(name string) (config.Profile, error) {
+	profileProvider := config.NewProfileProvider()
+	if name != "" {
+		return profileProvider.Profile(name)
+	}
+	return profileProvider.DefaultProfile()
+}
+
+// roleFromArn extracts the role name of an assumed role ARN (arn:aws:sts::<id>:assumed-role/<role>/<session>)
+func roleFromArn(arn string) string {
+	_, resource, found := strings.Cut(arn, ":assumed-role/")
+	if !found {
+		return "-"
+	}
+	role, _, _ := strings.Cut(resource, "/")
+	return role
+}
+
+func expiryText(expiration time.Time) string {
+	text := expiration.Local().Format(time.DateTime)
+	if expiration.Before(time.Now()) {
+		return text + " (expired)"
+	}
+	return text + fmt.Sprintf(" (in %s)", time.Until(expiration).Round(time.Minute))
+}
@@ -0,0 +1,120 @@
+package terraform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/IronFE/iron.cli/util"
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/apex/log"
+)
+
+const pluginCacheEnv = "TF_PLUGIN_CACHE_DIR"
+
+var moduleCacheInvalidChars = regexp.MustCompile("[^a-zA-Z0-9._-]")
+
+// the plugin cache is not safe for concurrent use, so parallel executions initialize one after another
+var pluginCacheMutex sync.Mutex
+
+// parallel executions of the same deployment share its cached modules
+var moduleCacheMutex sync.Mutex
+
+// PluginCacheDir returns the folder the providers are cached in, so they are only downloaded once for all working copies
+func PluginCacheDir() string {
+	return filepath.Join(config.BaseDir(), "plugin-cache")
+}
+
+// ModuleCacheDir returns the folder the modules of the deployments are cached in
+func ModuleCacheDir() string {
+	return filepath.Join(config.BaseDir(), "modules")
+}
+
+// InstallDir returns the folder the Terraform versions are installed in
+func InstallDir() string {
+	return filepath.Join(config.BaseDir(), "bin")
+}
+
+// withPluginCache sets the plugin cache unless the user configured another one
+func withPluginCache(env map[string]string) map[string]string {
+	if _, set := env[pluginCacheEnv]; set {
+		return env
+	}
+
+	if err := os.MkdirAll(PluginCacheDir(), 0755); err != nil {
+		log.WithError(err).Warn("failed to create the plugin cache")
+		return env
+	}
+	env[pluginCacheEnv] = PluginCacheDir()
+	return env
+}
+
+// moduleCachePath returns the cache folder of the modules of a deployment folder
+func moduleCachePath(sourceDir string) string {
+	hash := sha256.Sum256([]byte(sourceDir))
+	name := fmt.Sprintf("%s-%s", filepath.Base(sourceDir), hex.EncodeToString(hash[:])[:12])
+	return filepath.Join(ModuleCacheDir(), moduleCacheInvalidChars.ReplaceAllString(name, "-"))
+}
+
+// restoreModules copies the cached modules of the deployment into the working copy
+func restoreModules(sourceDir string, workDir string) error {
+	moduleCacheMutex.Lock()
+	defer moduleCacheMutex.Unlock()
+
+	cached := moduleCachePath(sourceDir)
+	if _, err := os.Stat(cached); err != nil {
+		return nil
+	}
+
+	modules := filepath.Join(workDir, ".terraform", "modules")
+	if _, err := os.Stat(modules); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(modules, 0755); err != nil {
+		return fmt.Errorf("failed to create the modules folder: %w", err)
+	}
+	if err := util.CopyFolder(cached, modules); err != nil {
+		return fmt.Errorf("failed to restore the cached modules: %w", err)
+	}
+	return nil
+}
+
+// storeModules replaces the cached modules of the deployment by the ones of the working copy
+func storeModules(sourceDir string, workDir string) error {
+	moduleCacheMutex.Lock()
+	defer moduleCacheMutex.Unlock()
+
+	modules := filepath.Join(workDir, ".terraform", "modules")
+	if _, err := os.Stat(modules); err != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(ModuleCacheDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create the module cache: %w", err)
+	}
+	tempDir, err := os.MkdirTemp(ModuleCacheDir(), ".store")
+	if err != nil {
+		return fmt.Errorf("failed to create the module cache: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tempDir)
+	}()
+
+	if err = util.CopyFolder(modules, tempDir); err != nil {
+		return fmt.Errorf("failed to cache the modules: %w", err)
+	}
+
+	cached := moduleCachePath(sourceDir)
+	if err = os.RemoveAll(cached); err != nil {
+		return fmt.Errorf("failed to replace the cached modules: %w", err)
+	}
+	if err = os.Rename(tempDir, cached); err != nil {
+		return fmt.Errorf("failed to cache the modules: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModuleCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sourceDir := filepath.Join(t.TempDir(), "network")
+
+	first := t.TempDir()
+	module := filepath.Join(first, ".terraform", "modules", "vpc")
+	if err := os.MkdirAll(module, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(module, "main.tf"), []byte("# vpc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := storeModules(sourceDir, first); err != nil {
+		t.Fatalf("storeModules() error = %v", err)
+	}
+
+	second := t.TempDir()
+	if err := restoreModules(sourceDir, second); err != nil {
+		t.Fatalf("restoreModules() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(second, ".terraform", "modules", "vpc", "main.tf"))
+	if err != nil {
+		t.Fatalf("restored module not found: %v", err)
+	}
+	if string(content) != "# vpc" {
+		t.Errorf("restored module = %q, want %q", content, "# vpc")
+	}
+
+	other := t.TempDir()
+	if err = restoreModules(filepath.Join(t.TempDir(), "data"), other); err != nil {
+		t.Fatalf("restoreModules() error = %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(other, ".terraform")); !os.IsNotExist(err) {
+		t.Errorf("modules of another deployment were restored")
+	}
+}
@@ -11,46 +11,57 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-func readTerraformConfig(workDir string) (*config.TerraformConfig, error) {
+// readTerraformConfig merges the global, the repository wide and the deployment config.
+// It returns the merged config and the files it was merged from in the order they were applied.
+func readTerraformConfig(workDir string) (*config.TerraformConfig, []string, error) {
 	profileProvider := config.NewProfileProvider()
 	cfg, err := profileProvider.Terraform()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read terraform defaults from config file")
+		return nil, nil, fmt.Errorf("failed to read terraform defaults from config file")
 	}
+	sources := []string{config.FilePath()}
 
 	gitRoot, err := git.GetRootDir(workDir)
 	if err != nil {
 		log.WithError(err).Warn("failed to get root of git")
 	} else {
 		searchPath := filepath.Join(gitRoot, "tf/config.yaml")
-		cfg, err = mergeWithFileConfig(searchPath, cfg)
+		var merged bool
+		cfg, merged, err = mergeWithFileConfig(searchPath, cfg)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		if merged {
+			sources = append(sources, searchPath)
 		}
 	}
 
 	searchPath := filepath.Join(workDir, "config.yaml")
-	cfg, err = mergeWithFileConfig(searchPath, cfg)
+	cfg, merged, err := mergeWithFileConfig(searchPath, cfg)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if merged {
+		sources = append(sources, searchPath)
 	}
 
-	return &cfg, nil
+	return &cfg, sources, nil
 }
 
-func mergeWithFileConfig(searchPath string, cfg config.TerraformConfig) (config.TerraformConfig, error) {
+func mergeWithFileConfig(searchPath string, cfg config.TerraformConfig) (config.TerraformConfig, bool, error) {
 	if _, err := os.Stat(searchPath); err == nil {
 		data, err := os.ReadFile(searchPath)
 		if err == nil {
 			repoConfig := config.TerraformConfig{}
 			if err = yaml.Unmarshal(data, &repoConfig); err == nil {
 				cfg.Merge(repoConfig)
+				return cfg, true, nil
 			} else {
-				return config.TerraformConfig{}, fmt.Errorf("failed to parse %s", searchPath)
+				return config.TerraformConfig{}, false, fmt.Errorf("failed to parse %s", searchPath)
 			}
 		} else {
 			log.WithError(err).Warnf("failed to read %s", searchPath)
 		}
 	}
-	return cfg, nil
+	return cfg, false, nil
 }
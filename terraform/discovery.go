@@ -0,0 +1,109 @@
+package terraform
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type Deployment struct {
+	Name       string   `json:"name"`
+	Directory  string   `json:"directory"`
+	BackendKey string   `json:"backendKey"`
+	Variants   []string `json:"variants"`
+	DependsOn  []string `json:"dependsOn,omitempty"`
+	// ConfigSources are the config files merged into the effective config, in the order they were applied
+	ConfigSources []string `json:"configSources"`
+}
+
+// DescribeDeployment reads the effective settings of the deployment in the given folder
+func DescribeDeployment(dir string) (Deployment, error) {
+	cfg, sources, err := readTerraformConfig(dir)
+	if err != nil {
+		return Deployment{}, err
+	}
+
+	variants, err := Variants(dir)
+	if err != nil {
+		return Deployment{}, err
+	}
+
+	name := effectiveDeploymentName(dir, "")
+	backendKey := cfg.Backend.Config["key"]
+	if cfg.Backend.Type == "s3" {
+		backendKey = name
+	}
+
+	return Deployment{
+		Name:          name,
+		Directory:     dir,
+		BackendKey:    backendKey,
+		Variants:      variants,
+		DependsOn:     cfg.DependsOn,
+		ConfigSources: sources,
+	}, nil
+}
+
+// Variants lists the names of all .tfvars files in the `variants` folder of the deployment
+func Variants(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "variants", "*.tfvars"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list variants of %s: %w", dir, err)
+	}
+
+	variants := make([]string, 0, len(files))
+	for _, file := range files {
+		variants = append(variants, strings.TrimSuffix(filepath.Base(file), ".tfvars"))
+	}
+	sort.Strings(variants)
+	return variants, nil
+}
+
+// DiscoverDeployments finds all deployment folders below root. A deployment folder contains .tf files.
+// Hidden folders, folders named `modules` and subfolders of deployments (e.g. local modules) are skipped.
+func DiscoverDeployments(root string) ([]string, error) {
+	var deployments []string
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+
+		name := entry.Name()
+		if path != root && (strings.HasPrefix(name, ".") || name == "modules") {
+			return filepath.SkipDir
+		}
+
+		isDeployment, err := containsTerraformFiles(path)
+		if err != nil {
+			return err
+		}
+		if isDeployment {
+			deployments = append(deployments, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	return deployments, err
+}
+
+func containsTerraformFiles(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".tf") || strings.HasSuffix(entry.Name(), ".tf.json")) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,45 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverDeployments(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"network/main.tf",
+		"network/modules/subnets/main.tf",
+		"apps/web/main.tf",
+		"apps/web/local/main.tf",
+		"apps/api/main.tf.json",
+		"apps/README.md",
+		"modules/shared/main.tf",
+		".tf12345/main.tf",
+	}
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deployments, err := DiscoverDeployments(root)
+	if err != nil {
+		t.Fatalf("DiscoverDeployments() error = %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(root, "apps/api"),
+		filepath.Join(root, "apps/web"),
+		filepath.Join(root, "network"),
+	}
+	if !reflect.DeepEqual(deployments, expected) {
+		t.Errorf("DiscoverDeployments() = %v, want %v", deployments, expected)
+	}
+}
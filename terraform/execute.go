@@ -3,10 +3,13 @@ package terraform
 import (
 	"context"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -25,12 +28,28 @@ import (
 //go:embed config.tf.tmpl
 var configTemplate string
 
+// ErrNoChanges can be returned by an action to signal that Terraform did not find any changes
+var ErrNoChanges = errors.New("no changes")
+
+const (
+	terraformRegistry = "registry.terraform.io/"
+	tofuRegistry      = "registry.opentofu.org/"
+)
+
+const lockFileName = ".terraform.lock.hcl"
+
+// the lock file is copied back into the deployment folder, which is shared by parallel executions
+var lockFileMutex sync.Mutex
+
 type ITerraformExecution interface {
 	Execute(action func(tf *tfexec.Terraform, options ExecutionOptions) error) error
 }
 
 type execution struct {
-	provider ITerraformProvider
+	provider       ITerraformProvider
+	awsAbstraction aws.IAws
+	logger         log.Interface
+	stdout         io.Writer
 
 	accountAlias   string
 	authProfile    string
@@ -38,15 +57,31 @@ type execution struct {
 	keepTemp       bool
 	pathArg        string
 	roleToAssume   string
+	roleChain      string
 	noRoleAssume   bool
+	session        aws.SessionOptions
 	mfa            bool
-	workDir        string
-	logLevel       string
-	variant        string
+	upgrade        bool
+	// staticCreds disables the credential server, which refreshes the credentials during long-running operations
+	staticCreds bool
+	workDir     string
+	logLevel    string
+	variant     string
 }
 
 type ExecutionOptions struct {
-	VariableFiles []string
+	VariableFiles  []string
+	AccountId      string
+	AccountName    string
+	DeploymentName string
+	Variant        string
+	BackendKey     string
+	// ConfigHash is the hash of the generated Terraform configuration (providers.tf)
+	ConfigHash string
+	// SourceDir is the deployment folder the working copy was created from
+	SourceDir string
+	// WorkDir is the working copy Terraform runs in
+	WorkDir string
 }
 
 type CliOptions struct {
@@ -57,70 +92,104 @@ type CliOptions struct {
 	Mfa            bool
 	NoRoleAssume   bool
 	RoleToAssume   string
+	// RoleChain is the name of a role chain of the profile or a list of `account:role` hops
+	RoleChain      string
+	Session        aws.SessionOptions
 	TargetAccount  string
-	WorkDir        string
-	Variant        string
+	TargetAccounts []string
+	Parallelism    int
+	// Upgrade updates the providers and modules to the newest versions allowed by the constraints
+	Upgrade bool
+	// Offline only uses installed Terraform versions
+	Offline bool
+	// StaticCreds passes the credentials as environment variables instead of serving them refreshable
+	StaticCreds bool
+	// TerraformOutput receives the output of Terraform; defaults to stdout
+	TerraformOutput io.Writer
+	WorkDir         string
+	Variant         string
 }
 
 func NewTerraformExecution(options *CliOptions) ITerraformExecution {
+	if len(options.TargetAccounts) > 0 {
+		return newMultiExecution(options)
+	}
+	return newExecution(options)
+}
+
+func newExecution(options *CliOptions) *execution {
 	return &execution{
-		provider:       NewTerraformProvider(),
+		provider:       NewTerraformProvider(options.Offline),
+		logger:         log.Log,
+		stdout:         options.terraformOutput(),
 		deploymentName: options.DeploymentName,
 		accountAlias:   options.TargetAccount,
 		authProfile:    options.AuthProfile,
 		mfa:            options.Mfa,
+		upgrade:        options.Upgrade,
 		noRoleAssume:   options.NoRoleAssume,
 		keepTemp:       options.KeepTempDir,
 		logLevel:       options.DebugLevel,
 		pathArg:        options.WorkDir,
 		roleToAssume:   options.RoleToAssume,
+		roleChain:      options.RoleChain,
+		session:        options.Session,
+		staticCreds:    options.StaticCreds,
 		variant:        options.Variant,
 	}
 }
 
+// effectiveDeploymentName returns the given name or the name of the deployment folder if nothing is given
+func effectiveDeploymentName(workDir string, name string) string {
+	if name != "" {
+		return name
+	}
+	pathParts := strings.Split(workDir, "/")
+	return pathParts[len(pathParts)-1]
+}
+
+func (o *CliOptions) terraformOutput() io.Writer {
+	if o.TerraformOutput != nil {
+		return o.TerraformOutput
+	}
+	return os.Stdout
+}
+
 func (e *execution) Execute(action func(tf *tfexec.Terraform, options ExecutionOptions) error) error {
-	awsAbstraction, err := aws.NewAws(e.authProfile)
-	if err != nil {
-		return err
+	err := e.execute(action)
+	if errors.Is(err, ErrNoChanges) {
+		return nil
 	}
+	return err
+}
 
+func (e *execution) execute(action func(tf *tfexec.Terraform, options ExecutionOptions) error) error {
+	var err error
 	if e.workDir, err = util.GetWorkDirFromArg(e.pathArg); err != nil {
 		return err
 	}
 
-	var access *aws.AwsAccountAccess
-
-	if e.noRoleAssume {
-		access, err = awsAbstraction.SessionToken(30 * time.Minute)
-		if err != nil {
-			return fmt.Errorf("failed to get session token for current user: %w", err)
-		}
-	} else {
-
-		if e.mfa {
-			access, err = awsAbstraction.AssumeRoleWithMfa(e.roleToAssume, e.accountAlias)
-		} else {
-			access, err = awsAbstraction.AssumeRole(e.roleToAssume, e.accountAlias)
-		}
-
-		if err != nil {
+	awsAbstraction := e.awsAbstraction
+	if awsAbstraction == nil {
+		session := e.session
+		session.Deployment = effectiveDeploymentName(e.workDir, e.deploymentName)
+		if awsAbstraction, err = aws.NewAwsWithSession(e.authProfile, session); err != nil {
 			return err
 		}
 	}
 
-	cfg, err := readTerraformConfig(e.workDir)
+	access, err := e.accountAccess(awsAbstraction, e.accountAlias)
 	if err != nil {
 		return err
 	}
 
-	var deploymentName string
-	if e.deploymentName != "" {
-		deploymentName = e.deploymentName
-	} else {
-		pathParts := strings.Split(e.workDir, "/")
-		deploymentName = pathParts[len(pathParts)-1]
+	cfg, _, err := readTerraformConfig(e.workDir)
+	if err != nil {
+		return err
 	}
 
+	deploymentName := effectiveDeploymentName(e.workDir, e.deploymentName)
+
 	tags := map[string]string{
 		"deployment": deploymentName,
 	}
@@ -142,29 +211,38 @@ func (e *execution) Execute(action func(tf *tfexec.Terraform, options ExecutionO
 		}
 	}
 
-	if cfg.Backend.Type == "s3" {
-		cfg.Backend.Config["key"] = deploymentName
-		if _, exists := cfg.Backend.Config["bucket"]; !exists {
-			cfg.Backend.Config["bucket"] = fmt.Sprintf("%s-tf-state", access.AccountId)
+	inputs, err := e.readInputs(awsAbstraction, access, *cfg)
+	if err != nil {
+		return err
+	}
+
+	cfg.Backend = backendFor(cfg.Backend, deploymentName, access.AccountId)
+
+	var credentialServer *aws.CredentialServer
+	if e.refreshCredentials(awsAbstraction) {
+		credentialServer, err = aws.ListenCredentialServer("127.0.0.1:0", access, func() (*aws.AwsAccountAccess, error) {
+			return e.accountAccess(awsAbstraction, e.accountAlias)
+		})
+		if err != nil {
+			return err
 		}
+		defer func() {
+			_ = credentialServer.Close()
+		}()
 	}
 
 	return e.onWorkingCopy(access, cfg, func(credentials *aws.AwsAccountAccess, workDir string) error {
-		tf, err := e.provider.Terraform(workDir)
+		tf, err := e.provider.Terraform(workDir, cfg)
 		if err != nil {
 			return err
 		}
+		tf.SetStdout(e.stdout)
 
-		userEnvs := lo.SliceToMap(os.Environ(), func(item string) (string, string) {
-			splits := strings.SplitN(item, "=", 2)
-			return splits[0], splits[1]
-		})
-
-		userEnvs["AWS_ACCESS_KEY_ID"] = credentials.AccessKeyId
-		userEnvs["AWS_SECRET_ACCESS_KEY"] = credentials.SecretKey
-		userEnvs["AWS_SESSION_TOKEN"] = credentials.SessionToken
-
-		if err = tf.SetEnv(userEnvs); err != nil {
+		env := terraformEnv(credentials)
+		if credentialServer != nil {
+			env = refreshingEnv(env, credentialServer)
+		}
+		if err = tf.SetEnv(env); err != nil {
 			return fmt.Errorf("failed to set environment variables for terraform: %w", err)
 		}
 
@@ -177,9 +255,20 @@ func (e *execution) Execute(action func(tf *tfexec.Terraform, options ExecutionO
 			}
 		}
 
-		err = tf.Init(context.Background(), tfexec.Upgrade(true))
-		if err != nil {
-			return errors.Wrap(err, "error running terraform init")
+		if cfg.ReuseModules {
+			if err = restoreModules(e.workDir, workDir); err != nil {
+				e.logger.WithError(err).Warn("cached modules are not used")
+			}
+		}
+
+		if err = e.init(tf); err != nil {
+			return err
+		}
+
+		if cfg.ReuseModules {
+			if err = storeModules(e.workDir, workDir); err != nil {
+				e.logger.WithError(err).Warn("failed to cache the modules")
+			}
 		}
 
 		variableFiles, err := e.variableFiles(workDir)
@@ -187,7 +276,28 @@ func (e *execution) Execute(action func(tf *tfexec.Terraform, options ExecutionO
 			return fmt.Errorf("the variant file can not be read: %w", err)
 		}
 
-		if err = action(tf, ExecutionOptions{VariableFiles: variableFiles}); err != nil {
+		if err = writeInputs(workDir, inputs); err != nil {
+			return err
+		}
+
+		configHash, err := util.HashFile(filepath.Join(workDir, "providers.tf"))
+		if err != nil {
+			return fmt.Errorf("failed to hash the terraform configuration: %w", err)
+		}
+
+		execOptions := ExecutionOptions{
+			VariableFiles:  variableFiles,
+			AccountId:      credentials.AccountId,
+			AccountName:    e.accountAlias,
+			DeploymentName: deploymentName,
+			Variant:        e.variant,
+			BackendKey:     cfg.Backend.Config["key"],
+			ConfigHash:     configHash,
+			SourceDir:      e.workDir,
+			WorkDir:        workDir,
+		}
+
+		if err = action(tf, execOptions); err != nil {
 			return err
 		}
 
@@ -195,6 +305,98 @@ func (e *execution) Execute(action func(tf *tfexec.Terraform, options ExecutionO
 	})
 }
 
+// init initializes the working copy. Providers are only upgraded if requested, otherwise the cached ones are used.
+func (e *execution) init(tf *tfexec.Terraform) error {
+	pluginCacheMutex.Lock()
+	defer pluginCacheMutex.Unlock()
+
+	if err := tf.Init(context.Background(), tfexec.Upgrade(e.upgrade)); err != nil {
+		return errors.Wrap(err, "error running terraform init")
+	}
+	return nil
+}
+
+// accountAccess returns credentials for the account as configured by the command line options
+func (e *execution) accountAccess(awsAbstraction aws.IAws, accountAlias string) (*aws.AwsAccountAccess, error) {
+	if e.noRoleAssume {
+		access, err := awsAbstraction.SessionToken(awsAbstraction.Session().DurationOr(30 * time.Minute))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session token for current user: %w", err)
+		}
+		return access, nil
+	}
+
+	if e.roleChain != "" {
+		hops, err := aws.ResolveRoleChain(e.authProfile, e.roleChain)
+		if err != nil {
+			return nil, err
+		}
+		return aws.AssumeRoleChain(awsAbstraction, hops, accountAlias, e.mfa)
+	}
+
+	if e.mfa {
+		return awsAbstraction.AssumeRoleWithMfa(e.roleToAssume, accountAlias)
+	}
+	return awsAbstraction.AssumeRole(e.roleToAssume, accountAlias)
+}
+
+// terraformEnv returns the environment of the current process with the given credentials and the shared plugin cache
+func terraformEnv(credentials *aws.AwsAccountAccess) map[string]string {
+	userEnvs := lo.SliceToMap(os.Environ(), func(item string) (string, string) {
+		splits := strings.SplitN(item, "=", 2)
+		return splits[0], splits[1]
+	})
+
+	userEnvs["AWS_ACCESS_KEY_ID"] = credentials.AccessKeyId
+	userEnvs["AWS_SECRET_ACCESS_KEY"] = credentials.SecretKey
+	userEnvs["AWS_SESSION_TOKEN"] = credentials.SessionToken
+	// the region of an account alias is the default of the providers and the backend
+	if credentials.Region != "" {
+		userEnvs["AWS_REGION"] = credentials.Region
+		userEnvs["AWS_DEFAULT_REGION"] = credentials.Region
+	}
+	return withPluginCache(userEnvs)
+}
+
+// refreshCredentials reports whether Terraform gets its credentials from a credential server. With MFA, new credentials
+// would require a token in the middle of the Terraform run. This also applies to session tokens of strategies which
+// require MFA for them.
+func (e *execution) refreshCredentials(awsAbstraction aws.IAws) bool {
+	if e.noRoleAssume && awsAbstraction.SessionTokenRequiresMfa() {
+		return false
+	}
+	return !e.staticCreds && !e.mfa
+}
+
+// refreshingEnv replaces the credentials of the environment (static ones, profiles and web identities) by the endpoint
+// of the credential server
+func refreshingEnv(env map[string]string, server *aws.CredentialServer) map[string]string {
+	for _, name := range aws.ShadowingEnvs {
+		delete(env, name)
+	}
+	maps.Copy(env, server.Env())
+	return env
+}
+
+// backendFor returns the backend config to store the state of the deployment in the account
+func backendFor(backend config.Backend, deploymentName string, accountId string) config.Backend {
+	result := config.Backend{
+		Type:   backend.Type,
+		Config: maps.Clone(backend.Config),
+	}
+
+	if result.Type == "s3" {
+		if result.Config == nil {
+			result.Config = map[string]string{}
+		}
+		result.Config["key"] = deploymentName
+		if _, exists := result.Config["bucket"]; !exists {
+			result.Config["bucket"] = fmt.Sprintf("%s-tf-state", accountId)
+		}
+	}
+	return result
+}
+
 func (e *execution) onWorkingCopy(account *aws.AwsAccountAccess, cfg *config.TerraformConfig, action func(account *aws.AwsAccountAccess, workDir string) error) error {
 
 	base := filepath.Join(e.workDir, "..")
@@ -204,8 +406,8 @@ func (e *execution) onWorkingCopy(account *aws.AwsAccountAccess, cfg *config.Ter
 		return errors.Wrap(err, "error while creating temp directory for terraform")
 	}
 
-	log.Infof("working on temp dir %s", dest)
-	log.Infof("AWS account id: %s, account name: %s", account.AccountId, e.accountAlias)
+	e.logger.Infof("working on temp dir %s", dest)
+	e.logger.Infof("AWS account id: %s, account name: %s", account.AccountId, e.accountAlias)
 	if err = util.CopyFolder(e.workDir, dest); err != nil {
 		return fmt.Errorf("error while copying terraform directory: %w", err)
 	}
@@ -216,18 +418,7 @@ func (e *execution) onWorkingCopy(account *aws.AwsAccountAccess, cfg *config.Ter
 
 	actionErr := action(account, dest)
 
-	lockFileName := ".terraform.lock.hcl"
-	lockFilePath := path.Join(dest, lockFileName)
-	lockFileExists, err := util.FileExists(lockFilePath)
-	if err != nil {
-		log.WithError(err).Warnf("terraform lock file existence could not be checked ")
-	} else {
-		if lockFileExists {
-			if err = util.CopyFile(lockFilePath, path.Join(e.workDir, lockFileName)); err != nil {
-				log.WithError(err).Warnf("terraform lock file could not be copied")
-			}
-		}
-	}
+	e.copyLockFile(dest)
 
 	if e.keepTemp {
 		return actionErr
@@ -240,6 +431,23 @@ func (e *execution) onWorkingCopy(account *aws.AwsAccountAccess, cfg *config.Ter
 	return actionErr
 }
 
+func (e *execution) copyLockFile(workDir string) {
+	lockFileMutex.Lock()
+	defer lockFileMutex.Unlock()
+
+	lockFilePath := path.Join(workDir, lockFileName)
+	lockFileExists, err := util.FileExists(lockFilePath)
+	if err != nil {
+		e.logger.WithError(err).Warnf("terraform lock file existence could not be checked ")
+	} else {
+		if lockFileExists {
+			if err = util.CopyFile(lockFilePath, path.Join(e.workDir, lockFileName)); err != nil {
+				e.logger.WithError(err).Warnf("terraform lock file could not be copied")
+			}
+		}
+	}
+}
+
 func (e *execution) addConfig(workDir string, cfg *config.TerraformConfig) error {
 
 	providersFile, err := os.Create(filepath.Join(workDir, "providers.tf"))
@@ -251,7 +459,7 @@ func (e *execution) addConfig(workDir string, cfg *config.TerraformConfig) error
 		_ = providersFile.Close()
 	}()
 
-	tmpl, err := template.New("terraformConfig").Parse(configTemplate)
+	tmpl, err := template.New("terraformConfig").Funcs(template.FuncMap{"providerSource": providerSource}).Parse(configTemplate)
 
 	if err != nil {
 		return err
@@ -260,6 +468,17 @@ func (e *execution) addConfig(workDir string, cfg *config.TerraformConfig) error
 	return err
 }
 
+// providerSource returns the source address of a provider for the engine. OpenTofu resolves sources without a host in
+// its own registry, but explicit addresses of the Terraform registry must be changed.
+func providerSource(engine string, source string) string {
+	if engine == config.EngineTofu {
+		if name, found := strings.CutPrefix(source, terraformRegistry); found {
+			return tofuRegistry + name
+		}
+	}
+	return source
+}
+
 func (e *execution) variableFiles(workDir string) ([]string, error) {
 	if e.variant == "" {
 		return []string{}, nil
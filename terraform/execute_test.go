@@ -0,0 +1,156 @@
+package terraform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/IronFE/iron.cli/util/config"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+func TestProviderSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine string
+		source string
+		want   string
+	}{
+		{name: "terraform", engine: config.EngineTerraform, source: "registry.terraform.io/hashicorp/aws", want: "registry.terraform.io/hashicorp/aws"},
+		{name: "tofu without host", engine: config.EngineTofu, source: "hashicorp/aws", want: "hashicorp/aws"},
+		{name: "tofu with terraform registry", engine: config.EngineTofu, source: "registry.terraform.io/hashicorp/aws", want: "registry.opentofu.org/hashicorp/aws"},
+		{name: "tofu with private registry", engine: config.EngineTofu, source: "registry.example.com/acme/aws", want: "registry.example.com/acme/aws"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := providerSource(tt.engine, tt.source); got != tt.want {
+				t.Errorf("providerSource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddConfigEncryption(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine string
+		want   bool
+	}{
+		{name: "terraform", engine: config.EngineTerraform, want: false},
+		{name: "tofu", engine: config.EngineTofu, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := &config.TerraformConfig{
+				Engine:     tt.engine,
+				Backend:    config.Backend{Type: "s3"},
+				Encryption: &config.Encryption{KmsKeyId: "alias/state", Region: "eu-central-1"},
+			}
+			if err := (&execution{}).addConfig(dir, cfg); err != nil {
+				t.Fatalf("addConfig() error = %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, "providers.tf"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(content), `kms_key_id = "alias/state"`); got != tt.want {
+				t.Errorf("addConfig() encryption = %v, want %v:\n%s", got, tt.want, content)
+			}
+		})
+	}
+}
+
+type mfaSessionTokens struct {
+	aws.IAws
+	requiresMfa bool
+}
+
+func (m mfaSessionTokens) SessionTokenRequiresMfa() bool {
+	return m.requiresMfa
+}
+
+func TestRefreshCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		execution   execution
+		requiresMfa bool
+		want        bool
+	}{
+		{name: "assumed role", execution: execution{}, want: true},
+		{name: "static credentials", execution: execution{staticCreds: true}, want: false},
+		{name: "mfa", execution: execution{mfa: true}, want: false},
+		{name: "session token without mfa", execution: execution{noRoleAssume: true}, want: true},
+		{name: "session token with mfa", execution: execution{noRoleAssume: true}, requiresMfa: true, want: false},
+		{name: "assumed role of strategy with mfa session tokens", execution: execution{}, requiresMfa: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.execution.refreshCredentials(mfaSessionTokens{requiresMfa: tt.requiresMfa}); got != tt.want {
+				t.Errorf("refreshCredentials() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerraformEnvRegion(t *testing.T) {
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	env := terraformEnv(&aws.AwsAccountAccess{AccessKeyId: "key", Region: "eu-central-1"})
+	if env["AWS_REGION"] != "eu-central-1" || env["AWS_DEFAULT_REGION"] != "eu-central-1" {
+		t.Errorf("terraformEnv() region = %q, %q, want the region of the account", env["AWS_REGION"], env["AWS_DEFAULT_REGION"])
+	}
+
+	env = terraformEnv(&aws.AwsAccountAccess{AccessKeyId: "key"})
+	if env["AWS_REGION"] != "us-east-1" {
+		t.Errorf("terraformEnv() region = %q, want the region of the environment", env["AWS_REGION"])
+	}
+}
+
+func TestRefreshingEnvDefaultProfile(t *testing.T) {
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(credentialsFile, []byte("[default]\naws_access_key_id = profile-key\naws_secret_access_key = profile-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := aws.ListenCredentialServer("127.0.0.1:0", &aws.AwsAccountAccess{
+		AccessKeyId:  "server-key",
+		SecretKey:    "server-secret",
+		SessionToken: "server-token",
+		Expiration:   time.Now().Add(time.Hour),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	env := refreshingEnv(map[string]string{
+		"AWS_SHARED_CREDENTIALS_FILE": credentialsFile,
+		"AWS_PROFILE":                 "default",
+		"AWS_ROLE_ARN":                "arn:aws:iam::123456789012:role/ci",
+		"AWS_WEB_IDENTITY_TOKEN_FILE": filepath.Join(dir, "token"),
+	}, server)
+
+	names := append([]string{"AWS_CONFIG_FILE", "AWS_SHARED_CREDENTIALS_FILE", "AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CONTAINER_AUTHORIZATION_TOKEN"}, aws.ShadowingEnvs...)
+	for _, name := range names {
+		t.Setenv(name, env[name])
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithRegion("eu-central-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials, err := cfg.Credentials.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if credentials.AccessKeyID != "server-key" {
+		t.Errorf("Retrieve() access key = %q, want the credentials of the server", credentials.AccessKeyID)
+	}
+}
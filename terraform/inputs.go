@@ -0,0 +1,176 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/IronFE/iron.cli/util/git"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const inputsFileName = "iron.auto.tfvars.json"
+
+// readInputs reads the outputs of other deployments which are configured as inputs of the deployment
+func (e *execution) readInputs(awsAbstraction aws.IAws, access *aws.AwsAccountAccess, cfg config.TerraformConfig) (map[string]json.RawMessage, error) {
+	if len(cfg.Inputs) == 0 {
+		return nil, nil
+	}
+
+	accesses := map[string]*aws.AwsAccountAccess{
+		"":             access,
+		e.accountAlias: access,
+	}
+	deploymentOutputs := map[string]map[string]tfexec.OutputMeta{}
+	values := map[string]json.RawMessage{}
+
+	names := lo.Keys(cfg.Inputs)
+	slices.Sort(names)
+
+	for _, name := range names {
+		input := cfg.Inputs[name]
+		if input.Deployment == "" || input.Output == "" {
+			return nil, errors.Errorf("input %q must reference a deployment and an output", name)
+		}
+
+		accountAccess, found := accesses[input.Account]
+		if !found {
+			if e.noRoleAssume {
+				return nil, errors.Errorf("input %q references account %q, which can not be accessed without assuming a role", name, input.Account)
+			}
+
+			var err error
+			if accountAccess, err = e.accountAccess(awsAbstraction, input.Account); err != nil {
+				return nil, fmt.Errorf("failed to access account %q for input %q: %w", input.Account, name, err)
+			}
+			accesses[input.Account] = accountAccess
+		}
+
+		key := fmt.Sprintf("%s/%s", accountAccess.AccountId, input.Deployment)
+		outputs, found := deploymentOutputs[key]
+		if !found {
+			var err error
+			if outputs, err = e.readDeploymentOutputs(accountAccess, input.Deployment); err != nil {
+				return nil, fmt.Errorf("failed to read outputs of deployment %q for input %q: %w", input.Deployment, name, err)
+			}
+			deploymentOutputs[key] = outputs
+		}
+
+		output, found := outputs[input.Output]
+		if !found {
+			return nil, errors.Errorf("deployment %q has no output %q for input %q", input.Deployment, input.Output, name)
+		}
+		values[name] = output.Value
+	}
+
+	return values, nil
+}
+
+// readDeploymentOutputs reads the outputs from the state of a deployment using a configuration containing only the
+// backend. The backend, engine and encryption are taken from the config of the deployment.
+func (e *execution) readDeploymentOutputs(access *aws.AwsAccountAccess, deploymentName string) (map[string]tfexec.OutputMeta, error) {
+	e.logger.Infof("reading outputs of deployment %s in account %s", deploymentName, access.AccountId)
+
+	deploymentDir, err := findDeployment(e.workDir, deploymentName)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := readTerraformConfig(deploymentDir)
+	if err != nil {
+		return nil, err
+	}
+	// only the key of the s3 backend is derived from the deployment name, other backends may share their config
+	if cfg.Backend.Type != "s3" {
+		return nil, errors.Errorf("the state of deployment %q can not be located, as it uses the %q backend instead of s3", deploymentName, cfg.Backend.Type)
+	}
+
+	dir, err := os.MkdirTemp("", ".tfinputs")
+	if err != nil {
+		return nil, errors.Wrap(err, "error while creating temp directory for terraform")
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	stateConfig := &config.TerraformConfig{
+		Engine:           cfg.Engine,
+		TerraformVersion: cfg.TerraformVersion,
+		Install:          cfg.Install,
+		Encryption:       cfg.Encryption,
+		Backend:          backendFor(cfg.Backend, deploymentName, access.AccountId),
+	}
+	if err = e.addConfig(dir, stateConfig); err != nil {
+		return nil, err
+	}
+
+	tf, err := e.provider.Terraform(dir, stateConfig)
+	if err != nil {
+		return nil, err
+	}
+	tf.SetStdout(io.Discard)
+
+	if err = tf.SetEnv(terraformEnv(access)); err != nil {
+		return nil, fmt.Errorf("failed to set environment variables for terraform: %w", err)
+	}
+
+	if err = e.init(tf); err != nil {
+		return nil, err
+	}
+
+	outputs, err := tf.Output(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run terraform output")
+	}
+	return outputs, nil
+}
+
+// findDeployment returns the folder of the deployment with the given name in the git repository of the work dir
+func findDeployment(workDir string, deploymentName string) (string, error) {
+	root, err := git.GetRootDir(workDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to find deployment %q: %w", deploymentName, err)
+	}
+
+	dirs, err := DiscoverDeployments(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to find deployment %q: %w", deploymentName, err)
+	}
+
+	matches := lo.Filter(dirs, func(dir string, _ int) bool {
+		return filepath.Base(dir) == deploymentName
+	})
+	switch len(matches) {
+	case 0:
+		return "", errors.Errorf("no deployment %q found below %s", deploymentName, root)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", errors.Errorf("deployment %q is ambiguous: %s", deploymentName, strings.Join(matches, ", "))
+	}
+}
+
+// writeInputs stores the input values as variables file, which is loaded by Terraform automatically
+func writeInputs(workDir string, inputs map[string]json.RawMessage) error {
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(inputs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal inputs: %w", err)
+	}
+
+	if err = os.WriteFile(filepath.Join(workDir, inputsFileName), data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", inputsFileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package terraform
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindDeployment(t *testing.T) {
+	root := t.TempDir()
+	if err := exec.Command("git", "init", "-q", root).Run(); err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	for _, file := range []string{"network/main.tf", "apps/web/main.tf", "legacy/web/main.tf"} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workDir := filepath.Join(root, "apps", "web")
+
+	dir, err := findDeployment(workDir, "network")
+	if err != nil {
+		t.Fatalf("findDeployment() error = %v", err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(filepath.Join(root, "network")); dir != resolved && dir != filepath.Join(root, "network") {
+		t.Errorf("findDeployment() = %s, want %s", dir, resolved)
+	}
+
+	if _, err = findDeployment(workDir, "web"); err == nil {
+		t.Error("findDeployment() of an ambiguous name should fail")
+	}
+	if _, err = findDeployment(workDir, "database"); err == nil {
+		t.Error("findDeployment() of a missing deployment should fail")
+	}
+}
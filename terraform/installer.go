@@ -0,0 +1,182 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/apex/log"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hc-install/product"
+	"github.com/hashicorp/hc-install/releases"
+	"github.com/pkg/errors"
+)
+
+const defaultReleasesUrl = "https://releases.hashicorp.com"
+
+// parallel executions require the same version, which is installed only once
+var installMutex sync.Mutex
+
+// installer provides the Terraform version matching a constraint. Versions are installed into `~/.iron-cli/bin/<version>`.
+type installer struct {
+	dir     string
+	mirror  string
+	offline bool
+}
+
+func newInstaller(install config.TerraformInstall) *installer {
+	return &installer{
+		dir:     InstallDir(),
+		mirror:  strings.TrimSuffix(install.Mirror, "/"),
+		offline: install.Offline,
+	}
+}
+
+// ExecPath returns the executable of the newest installed version matching the constraint. If there is none, the
+// newest release matching the constraint is downloaded, verified and installed.
+func (i *installer) ExecPath(constraint string) (string, error) {
+	constraints, err := version.NewConstraint(constraint)
+	if err != nil {
+		return "", fmt.Errorf("invalid terraform version constraint %q: %w", constraint, err)
+	}
+
+	installMutex.Lock()
+	defer installMutex.Unlock()
+
+	if v, execPath := i.installed(constraints); execPath != "" {
+		log.Debugf("using installed terraform %s", v)
+		return execPath, nil
+	}
+
+	if i.offline {
+		return "", errors.Errorf("no installed terraform version in %s matches %q and installing is disabled in offline mode", i.dir, constraint)
+	}
+
+	v, err := i.latestRelease(constraints)
+	if err != nil {
+		return "", err
+	}
+	return i.install(v)
+}
+
+// installed returns the newest installed version matching the constraints
+func (i *installer) installed(constraints version.Constraints) (*version.Version, string) {
+	entries, err := os.ReadDir(i.dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WithError(err).Warnf("failed to list installed terraform versions")
+		}
+		return nil, ""
+	}
+
+	var newest *version.Version
+	for _, entry := range entries {
+		v, err := version.NewVersion(entry.Name())
+		if err != nil || !entry.IsDir() || !constraints.Check(v) {
+			continue
+		}
+		if _, err = os.Stat(i.execPath(v)); err != nil {
+			continue
+		}
+		if newest == nil || v.GreaterThan(newest) {
+			newest = v
+		}
+	}
+
+	if newest == nil {
+		return nil, ""
+	}
+	return newest, i.execPath(newest)
+}
+
+// latestRelease returns the newest release matching the constraints from the index of the releases site
+func (i *installer) latestRelease(constraints version.Constraints) (*version.Version, error) {
+	url := fmt.Sprintf("%s/%s/index.json", i.releasesUrl(), product.Terraform.Name)
+
+	client := http.Client{Timeout: 30 * time.Second}
+	response, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list terraform releases: %w", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to list terraform releases: %s returned %s", url, response.Status)
+	}
+
+	index := struct {
+		Versions map[string]json.RawMessage `json:"versions"`
+	}{}
+	if err = json.NewDecoder(response.Body).Decode(&index); err != nil {
+		return nil, fmt.Errorf("failed to parse the terraform releases: %w", err)
+	}
+
+	var latest *version.Version
+	for name := range index.Versions {
+		v, err := version.NewVersion(name)
+		// enterprise builds have metadata
+		if err != nil || v.Metadata() != "" || !constraints.Check(v) {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+	}
+
+	if latest == nil {
+		return nil, errors.Errorf("no terraform release matches %q", constraints)
+	}
+	return latest, nil
+}
+
+// install downloads the version and verifies its checksum and signature. The version only shows up as installed after
+// it is complete.
+func (i *installer) install(v *version.Version) (string, error) {
+	log.Infof("installing terraform %s into %s", v, i.dir)
+
+	if err := os.MkdirAll(i.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create the terraform installation folder: %w", err)
+	}
+
+	tempDir, err := os.MkdirTemp(i.dir, ".install")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the terraform installation folder: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tempDir)
+	}()
+
+	exactVersion := &releases.ExactVersion{
+		Product:    product.Terraform,
+		Version:    v,
+		InstallDir: tempDir,
+		ApiBaseURL: i.mirror,
+	}
+	if _, err = exactVersion.Install(context.Background()); err != nil {
+		return "", fmt.Errorf("failed to install terraform %s: %w", v, err)
+	}
+
+	if err = os.Rename(tempDir, filepath.Join(i.dir, v.String())); err != nil {
+		return "", fmt.Errorf("failed to install terraform %s: %w", v, err)
+	}
+	return i.execPath(v), nil
+}
+
+func (i *installer) releasesUrl() string {
+	if i.mirror != "" {
+		return i.mirror
+	}
+	return defaultReleasesUrl
+}
+
+func (i *installer) execPath(v *version.Version) string {
+	return filepath.Join(i.dir, v.String(), product.Terraform.BinaryName())
+}
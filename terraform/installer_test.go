@@ -0,0 +1,59 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hc-install/product"
+)
+
+func TestInstallerInstalled(t *testing.T) {
+	dir := t.TempDir()
+	for _, v := range []string{"1.5.7", "1.6.2", "1.7.0"} {
+		if err := os.MkdirAll(filepath.Join(dir, v), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, v, product.Terraform.BinaryName()), nil, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// incomplete installations are ignored
+	if err := os.MkdirAll(filepath.Join(dir, "1.6.5"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		constraint string
+		want       string
+	}{
+		{name: "newest", constraint: ">= 1.2.0", want: "1.7.0"},
+		{name: "pessimistic", constraint: "~> 1.6.0", want: "1.6.2"},
+		{name: "exact", constraint: "1.5.7", want: "1.5.7"},
+		{name: "none", constraint: ">= 2.0.0", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraints, err := version.NewConstraint(tt.constraint)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, execPath := (&installer{dir: dir}).installed(constraints)
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("installed() = %v, want none", got)
+				}
+				return
+			}
+			if got == nil || got.String() != tt.want {
+				t.Fatalf("installed() = %v, want %v", got, tt.want)
+			}
+			if want := filepath.Join(dir, tt.want, product.Terraform.BinaryName()); execPath != want {
+				t.Errorf("installed() path = %v, want %v", execPath, want)
+			}
+		})
+	}
+}
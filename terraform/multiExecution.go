@@ -0,0 +1,183 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/IronFE/iron.cli/util"
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/apex/log"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const defaultParallelism = 4
+
+type accountStatus string
+
+const (
+	statusSuccess   accountStatus = "success"
+	statusNoChanges accountStatus = "no changes"
+	statusFailure   accountStatus = "failure"
+)
+
+type accountResult struct {
+	account string
+	status  accountStatus
+	err     error
+}
+
+type multiExecution struct {
+	options *CliOptions
+}
+
+func newMultiExecution(options *CliOptions) *multiExecution {
+	return &multiExecution{options: options}
+}
+
+func (m *multiExecution) Execute(action func(tf *tfexec.Terraform, options ExecutionOptions) error) error {
+	accounts, err := resolveAccounts(config.NewProfileProvider(), m.options.TargetAccounts)
+	if err != nil {
+		return err
+	}
+
+	workDir, err := util.GetWorkDirFromArg(m.options.WorkDir)
+	if err != nil {
+		return err
+	}
+
+	// all executions share the same authentication, so a login happens only once
+	session := m.options.Session
+	session.Deployment = effectiveDeploymentName(workDir, m.options.DeploymentName)
+	awsAbstraction, err := aws.NewAwsWithSession(m.options.AuthProfile, session)
+	if err != nil {
+		return err
+	}
+
+	// the same account under different names would run in parallel on the same state
+	accounts = uniqueAccounts(awsAbstraction, accounts)
+
+	parallelism := m.options.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+	if m.options.Mfa && parallelism > 1 {
+		log.Warn("MFA tokens are requested interactively; running accounts sequentially")
+		parallelism = 1
+	}
+
+	log.Infof("running on %d accounts with a parallelism of %d", len(accounts), parallelism)
+
+	results := make([]accountResult, len(accounts))
+	semaphore := make(chan struct{}, parallelism)
+	var waitGroup sync.WaitGroup
+
+	for i, account := range accounts {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			results[i] = m.executeOnAccount(account, awsAbstraction, action)
+		}()
+	}
+	waitGroup.Wait()
+
+	printSummary(m.options.terraformOutput(), results)
+
+	failed := lo.CountBy(results, func(result accountResult) bool {
+		return result.status == statusFailure
+	})
+	if failed > 0 {
+		return fmt.Errorf("execution failed on %d of %d accounts", failed, len(results))
+	}
+	return nil
+}
+
+func (m *multiExecution) executeOnAccount(account string, awsAbstraction aws.IAws, action func(tf *tfexec.Terraform, options ExecutionOptions) error) accountResult {
+	accountOptions := *m.options
+	accountOptions.TargetAccount = account
+	accountOptions.TargetAccounts = nil
+
+	stdout := util.NewPrefixWriter(m.options.terraformOutput(), fmt.Sprintf("[%s] ", account))
+
+	e := newExecution(&accountOptions)
+	e.awsAbstraction = awsAbstraction
+	e.logger = log.WithField("account", account)
+	e.stdout = stdout
+
+	err := e.execute(action)
+	if flushErr := stdout.Flush(); flushErr != nil {
+		e.logger.WithError(flushErr).Warn("failed to write terraform output")
+	}
+
+	switch {
+	case errors.Is(err, ErrNoChanges):
+		return accountResult{account: account, status: statusNoChanges}
+	case err != nil:
+		e.logger.WithError(err).Error("execution failed")
+		return accountResult{account: account, status: statusFailure, err: err}
+	default:
+		return accountResult{account: account, status: statusSuccess}
+	}
+}
+
+// resolveAccounts replaces account group names by their members and removes duplicates, ignoring the case
+func resolveAccounts(profileProvider config.IProvider, names []string) ([]string, error) {
+	groups, err := profileProvider.AccountGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account groups: %w", err)
+	}
+
+	var accounts []string
+	for _, name := range names {
+		if members, found := groups[name]; found {
+			if len(members) == 0 {
+				return nil, fmt.Errorf("account group %q has no accounts", name)
+			}
+			accounts = append(accounts, members...)
+		} else {
+			accounts = append(accounts, name)
+		}
+	}
+
+	return lo.UniqBy(accounts, strings.ToLower), nil
+}
+
+// uniqueAccounts removes accounts which resolve to the id of a previous account. Accounts which can not be resolved are
+// kept, so their execution reports the error.
+func uniqueAccounts(awsAbstraction aws.IAws, accounts []string) []string {
+	names := map[string]string{}
+	return lo.Filter(accounts, func(account string, _ int) bool {
+		id, err := awsAbstraction.FindAccountId(account)
+		if err != nil {
+			return true
+		}
+		if previous, found := names[id]; found {
+			log.Warnf("skipping account %q, which is the same as %q (%s)", account, previous, id)
+			return false
+		}
+		names[id] = account
+		return true
+	})
+}
+
+func printSummary(out io.Writer, results []accountResult) {
+	writer := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "\nACCOUNT\tSTATUS\tDETAILS")
+	for _, result := range results {
+		details := ""
+		if result.err != nil {
+			// terraform errors span multiple lines; the full error was already logged
+			details = strings.SplitN(result.err.Error(), "\n", 2)[0]
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\n", result.account, result.status, details)
+	}
+	_ = writer.Flush()
+}
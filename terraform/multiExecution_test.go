@@ -0,0 +1,57 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/IronFE/iron.cli/util/aws"
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/pkg/errors"
+)
+
+type groupProvider struct {
+	config.IProvider
+	groups map[string][]string
+}
+
+func (p groupProvider) AccountGroups() (map[string][]string, error) {
+	return p.groups, nil
+}
+
+type accountIds struct {
+	aws.IAws
+	ids map[string]string
+}
+
+func (a accountIds) FindAccountId(alias string) (string, error) {
+	if id, found := a.ids[alias]; found {
+		return id, nil
+	}
+	return "", errors.Errorf("account %q not found", alias)
+}
+
+func TestResolveAccounts(t *testing.T) {
+	provider := groupProvider{groups: map[string][]string{"prod": {"pay-prod", "Shop-Prod"}}}
+
+	got, err := resolveAccounts(provider, []string{"Pay-Prod", "prod", "shop-prod"})
+	if err != nil {
+		t.Fatalf("resolveAccounts() error = %v", err)
+	}
+	if want := []string{"Pay-Prod", "Shop-Prod"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveAccounts() = %v, want %v", got, want)
+	}
+}
+
+func TestUniqueAccounts(t *testing.T) {
+	awsAbstraction := accountIds{ids: map[string]string{
+		"pay-prod":     "111111111111",
+		"payments":     "111111111111",
+		"111111111111": "111111111111",
+		"shop-prod":    "222222222222",
+	}}
+
+	got := uniqueAccounts(awsAbstraction, []string{"pay-prod", "shop-prod", "payments", "unknown", "111111111111"})
+	if want := []string{"pay-prod", "shop-prod", "unknown"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("uniqueAccounts() = %v, want %v", got, want)
+	}
+}
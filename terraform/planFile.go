@@ -0,0 +1,247 @@
+package terraform
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/IronFE/iron.cli/util"
+	"github.com/IronFE/iron.cli/util/git"
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	planFileEntry     = "plan.tfplan"
+	metadataFileEntry = "metadata.json"
+)
+
+// PlanMetadata describes the circumstances a saved plan was created in
+type PlanMetadata struct {
+	AccountId      string    `json:"accountId"`
+	DeploymentName string    `json:"deploymentName"`
+	Variant        string    `json:"variant,omitempty"`
+	BackendKey     string    `json:"backendKey"`
+	GitCommit      string    `json:"gitCommit,omitempty"`
+	ConfigHash     string    `json:"configHash"`
+	SourceHash     string    `json:"sourceHash"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// NewPlanMetadata collects the metadata of the current execution. The excluded paths are not part of the source hash.
+func NewPlanMetadata(options ExecutionOptions, exclude ...string) (PlanMetadata, error) {
+	sourceHash, err := sourceHash(options, exclude...)
+	if err != nil {
+		return PlanMetadata{}, err
+	}
+
+	gitCommit, err := git.CurrentCommit(options.SourceDir)
+	if err != nil {
+		log.WithError(err).Warn("failed to get the current git commit")
+	}
+
+	return PlanMetadata{
+		AccountId:      options.AccountId,
+		DeploymentName: options.DeploymentName,
+		Variant:        options.Variant,
+		BackendKey:     options.BackendKey,
+		GitCommit:      gitCommit,
+		ConfigHash:     options.ConfigHash,
+		SourceHash:     sourceHash,
+		CreatedAt:      time.Now().UTC(),
+	}, nil
+}
+
+// sourceHash covers the deployment folder, the lock file and all modules Terraform loaded. The lock file is taken from
+// the working copy, as init may change it before it is copied back into the deployment folder.
+func sourceHash(options ExecutionOptions, exclude ...string) (string, error) {
+	workDir := options.WorkDir
+	if workDir == "" {
+		workDir = options.SourceDir
+	}
+
+	folderHash, err := util.HashFolder(options.SourceDir, append(exclude, filepath.Join(options.SourceDir, lockFileName))...)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%s\x00", folderHash)
+
+	lockHash, err := util.HashFile(filepath.Join(workDir, lockFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to hash the lock file: %w", err)
+	}
+	_, _ = fmt.Fprintf(hash, "%s\x00", lockHash)
+
+	modules, err := readModuleManifest(workDir)
+	if err != nil {
+		return "", err
+	}
+	for _, module := range modules {
+		moduleHash, err := util.HashFolder(filepath.Join(workDir, module.Dir))
+		if err != nil {
+			return "", fmt.Errorf("failed to hash module %q: %w", module.Key, err)
+		}
+		_, _ = fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%s\x00", module.Key, module.Source, module.Version, moduleHash)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// moduleManifestEntry is a module installed by terraform init, either a local folder (e.g. `../modules/vpc`) or a
+// downloaded module in `.terraform/modules`
+type moduleManifestEntry struct {
+	Key     string `json:"Key"`
+	Source  string `json:"Source"`
+	Version string `json:"Version"`
+	Dir     string `json:"Dir"`
+}
+
+// readModuleManifest returns the modules called by the configuration sorted by their key, without the root module
+func readModuleManifest(workDir string) ([]moduleManifestEntry, error) {
+	content, err := os.ReadFile(filepath.Join(workDir, ".terraform", "modules", "modules.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the module manifest: %w", err)
+	}
+
+	manifest := struct {
+		Modules []moduleManifestEntry `json:"Modules"`
+	}{}
+	if err = json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse the module manifest: %w", err)
+	}
+
+	modules := lo.Filter(manifest.Modules, func(module moduleManifestEntry, _ int) bool {
+		return module.Key != ""
+	})
+	slices.SortFunc(modules, func(a, b moduleManifestEntry) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return modules, nil
+}
+
+// Verify ensures a saved plan is applied to the same target and source it was created for
+func (m PlanMetadata) Verify(current PlanMetadata) error {
+	if m.AccountId != current.AccountId {
+		return errors.Errorf("the plan was created for account %s, but the target account is %s", m.AccountId, current.AccountId)
+	}
+	if m.BackendKey != current.BackendKey {
+		return errors.Errorf("the plan was created for backend key %q, but the current backend key is %q", m.BackendKey, current.BackendKey)
+	}
+	if m.SourceHash != current.SourceHash {
+		return errors.Errorf("the source files changed since the plan was created (commit %q)", m.GitCommit)
+	}
+
+	if m.ConfigHash != current.ConfigHash {
+		log.Warn("the generated terraform configuration differs from the one the plan was created with")
+	}
+	if m.Variant != current.Variant {
+		log.Warnf("the plan was created with variant %q, but variant %q is selected", m.Variant, current.Variant)
+	}
+	return nil
+}
+
+// ExportPlan bundles the binary Terraform plan and its metadata into a single file. An incomplete file is removed.
+func ExportPlan(planPath string, metadata PlanMetadata, destination string) error {
+	file, err := os.Create(destination)
+	if err != nil {
+		return fmt.Errorf("failed to create plan file %s: %w", destination, err)
+	}
+
+	err = writePlanBundle(file, planPath, metadata)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write plan file %s: %w", destination, closeErr)
+	}
+	if err != nil {
+		_ = os.Remove(destination)
+		return err
+	}
+	return nil
+}
+
+func writePlanBundle(file io.Writer, planPath string, metadata PlanMetadata) error {
+	archive := zip.NewWriter(file)
+
+	metadataWriter, err := archive.Create(metadataFileEntry)
+	if err != nil {
+		return fmt.Errorf("failed to add metadata to plan file: %w", err)
+	}
+	encoder := json.NewEncoder(metadataWriter)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(metadata); err != nil {
+		return fmt.Errorf("failed to marshal plan metadata: %w", err)
+	}
+
+	planWriter, err := archive.Create(planFileEntry)
+	if err != nil {
+		return fmt.Errorf("failed to add plan to plan file: %w", err)
+	}
+	plan, err := os.Open(planPath)
+	if err != nil {
+		return fmt.Errorf("failed to open terraform plan: %w", err)
+	}
+	defer plan.Close()
+	if _, err = io.Copy(planWriter, plan); err != nil {
+		return fmt.Errorf("failed to add plan to plan file: %w", err)
+	}
+
+	if err = archive.Close(); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+	return nil
+}
+
+// ImportPlan extracts the binary Terraform plan of a file created by ExportPlan to planPath and returns its metadata.
+// An incomplete plan is removed.
+func ImportPlan(source string, planPath string) (PlanMetadata, error) {
+	archive, err := zip.OpenReader(source)
+	if err != nil {
+		return PlanMetadata{}, fmt.Errorf("failed to open plan file %s: %w", source, err)
+	}
+	defer archive.Close()
+
+	metadataFile, err := archive.Open(metadataFileEntry)
+	if err != nil {
+		return PlanMetadata{}, fmt.Errorf("the plan file %s contains no metadata: %w", source, err)
+	}
+	defer metadataFile.Close()
+
+	metadata := PlanMetadata{}
+	if err = json.NewDecoder(metadataFile).Decode(&metadata); err != nil {
+		return PlanMetadata{}, fmt.Errorf("failed to parse plan metadata: %w", err)
+	}
+
+	planFile, err := archive.Open(planFileEntry)
+	if err != nil {
+		return PlanMetadata{}, fmt.Errorf("the plan file %s contains no terraform plan: %w", source, err)
+	}
+	defer planFile.Close()
+
+	plan, err := os.Create(planPath)
+	if err != nil {
+		return PlanMetadata{}, fmt.Errorf("failed to create terraform plan: %w", err)
+	}
+
+	_, err = io.Copy(plan, planFile)
+	if closeErr := plan.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(planPath)
+		return PlanMetadata{}, fmt.Errorf("failed to extract terraform plan: %w", err)
+	}
+
+	return metadata, nil
+}
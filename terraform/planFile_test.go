@@ -0,0 +1,140 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExportImportPlan(t *testing.T) {
+	dir := t.TempDir()
+	planPath := filepath.Join(dir, "plan")
+	if err := os.WriteFile(planPath, []byte("binary plan"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := PlanMetadata{
+		AccountId:      "123456789012",
+		DeploymentName: "network",
+		BackendKey:     "network",
+		ConfigHash:     "config",
+		SourceHash:     "source",
+		CreatedAt:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	bundlePath := filepath.Join(dir, "bundle.tfplan")
+	if err := ExportPlan(planPath, metadata, bundlePath); err != nil {
+		t.Fatalf("ExportPlan() error = %v", err)
+	}
+
+	extractedPath := filepath.Join(dir, "extracted")
+	imported, err := ImportPlan(bundlePath, extractedPath)
+	if err != nil {
+		t.Fatalf("ImportPlan() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(imported, metadata) {
+		t.Errorf("ImportPlan() = %v, want %v", imported, metadata)
+	}
+
+	content, err := os.ReadFile(extractedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "binary plan" {
+		t.Errorf("extracted plan = %q, want %q", content, "binary plan")
+	}
+}
+
+func TestPlanMetadata_Verify(t *testing.T) {
+	saved := PlanMetadata{AccountId: "1", BackendKey: "app", SourceHash: "abc", ConfigHash: "cfg"}
+
+	tests := []struct {
+		name    string
+		current PlanMetadata
+		wantErr bool
+	}{
+		{name: "Same target", current: saved},
+		{name: "Config differs", current: PlanMetadata{AccountId: "1", BackendKey: "app", SourceHash: "abc", ConfigHash: "other"}},
+		{name: "Account differs", current: PlanMetadata{AccountId: "2", BackendKey: "app", SourceHash: "abc"}, wantErr: true},
+		{name: "Backend key differs", current: PlanMetadata{AccountId: "1", BackendKey: "other", SourceHash: "abc"}, wantErr: true},
+		{name: "Source differs", current: PlanMetadata{AccountId: "1", BackendKey: "app", SourceHash: "def"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := saved.Verify(tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSourceHash(t *testing.T) {
+	root := t.TempDir()
+	sourceDir := filepath.Join(root, "app")
+	workDir := filepath.Join(root, ".tf123")
+	moduleDir := filepath.Join(root, "modules", "vpc")
+	for _, dir := range []string{sourceDir, filepath.Join(workDir, ".terraform", "modules"), moduleDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(filepath.Join(sourceDir, "main.tf"), `module "vpc" { source = "../modules/vpc" }`)
+	writeFile(filepath.Join(workDir, lockFileName), `provider "registry.terraform.io/hashicorp/aws" { version = "5.0.0" }`)
+	writeFile(filepath.Join(workDir, ".terraform", "modules", "modules.json"),
+		`{"Modules":[{"Key":"","Source":"","Dir":"."},{"Key":"vpc","Source":"../modules/vpc","Dir":"../modules/vpc"}]}`)
+	writeFile(filepath.Join(moduleDir, "main.tf"), `resource "aws_vpc" "this" {}`)
+
+	options := ExecutionOptions{SourceDir: sourceDir, WorkDir: workDir}
+	hash := func() string {
+		t.Helper()
+		got, err := sourceHash(options)
+		if err != nil {
+			t.Fatalf("sourceHash() error = %v", err)
+		}
+		return got
+	}
+
+	original := hash()
+	if hash() != original {
+		t.Fatal("sourceHash() is not stable")
+	}
+
+	writeFile(filepath.Join(sourceDir, lockFileName), "copied back")
+	if hash() != original {
+		t.Error("the lock file in the deployment folder changed the hash, but the one of the working copy is used")
+	}
+
+	writeFile(filepath.Join(workDir, lockFileName), `provider "registry.terraform.io/hashicorp/aws" { version = "5.1.0" }`)
+	lockChanged := hash()
+	if lockChanged == original {
+		t.Error("a changed lock file did not change the hash")
+	}
+
+	writeFile(filepath.Join(moduleDir, "main.tf"), `resource "aws_vpc" "this" { cidr_block = "10.0.0.0/16" }`)
+	if hash() == lockChanged {
+		t.Error("a changed local module did not change the hash")
+	}
+}
+
+func TestExportPlanRemovesIncompleteFile(t *testing.T) {
+	dir := t.TempDir()
+	bundlePath := filepath.Join(dir, "bundle.tfplan")
+
+	if err := ExportPlan(filepath.Join(dir, "missing"), PlanMetadata{}, bundlePath); err == nil {
+		t.Fatal("ExportPlan() expected an error for a missing plan")
+	}
+	if _, err := os.Stat(bundlePath); !os.IsNotExist(err) {
+		t.Errorf("the incomplete plan file %s was not removed", bundlePath)
+	}
+}
@@ -0,0 +1,349 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const (
+	sensitiveValue = "(sensitive)"
+	unknownValue   = "(known after apply)"
+)
+
+type PlanSummary struct {
+	Account    string                `json:"account,omitempty"`
+	AccountId  string                `json:"accountId,omitempty"`
+	Deployment string                `json:"deployment,omitempty"`
+	Counts     map[string]int        `json:"counts"`
+	Groups     []ResourceGroup       `json:"groups"`
+	Outputs    []OutputChangeSummary `json:"outputs"`
+}
+
+// ResourceGroup contains all changed resources of the same type in the same module
+type ResourceGroup struct {
+	Module    string                  `json:"module,omitempty"`
+	Type      string                  `json:"type"`
+	Resources []ResourceChangeSummary `json:"resources"`
+}
+
+type ResourceChangeSummary struct {
+	Address    string            `json:"address"`
+	Action     string            `json:"action"`
+	Attributes []AttributeChange `json:"attributes,omitempty"`
+}
+
+type AttributeChange struct {
+	Name   string      `json:"name"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type OutputChangeSummary struct {
+	Name   string      `json:"name"`
+	Action string      `json:"action"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+func NewPlanSummary(plan *tfjson.Plan, options ExecutionOptions) PlanSummary {
+	summary := PlanSummary{
+		Account:    options.AccountName,
+		AccountId:  options.AccountId,
+		Deployment: options.DeploymentName,
+		Counts:     map[string]int{"create": 0, "update": 0, "replace": 0, "destroy": 0},
+		Groups:     []ResourceGroup{},
+		Outputs:    []OutputChangeSummary{},
+	}
+
+	groups := map[string]*ResourceGroup{}
+	var groupKeys []string
+
+	for _, resource := range plan.ResourceChanges {
+		if resource.Change == nil || resource.Mode != tfjson.ManagedResourceMode {
+			continue
+		}
+		action := actionName(resource.Change.Actions)
+		if action == "" {
+			continue
+		}
+		summary.Counts[action]++
+
+		key := resource.ModuleAddress + "\x00" + resource.Type
+		group, found := groups[key]
+		if !found {
+			group = &ResourceGroup{Module: resource.ModuleAddress, Type: resource.Type}
+			groups[key] = group
+			groupKeys = append(groupKeys, key)
+		}
+
+		resourceSummary := ResourceChangeSummary{Address: resource.Address, Action: action}
+		if action != "destroy" {
+			resourceSummary.Attributes = attributeChanges(resource.Change)
+		}
+		group.Resources = append(group.Resources, resourceSummary)
+	}
+
+	sort.Strings(groupKeys)
+	for _, key := range groupKeys {
+		summary.Groups = append(summary.Groups, *groups[key])
+	}
+
+	outputNames := make([]string, 0, len(plan.OutputChanges))
+	for name := range plan.OutputChanges {
+		outputNames = append(outputNames, name)
+	}
+	sort.Strings(outputNames)
+
+	for _, name := range outputNames {
+		change := plan.OutputChanges[name]
+		action := actionName(change.Actions)
+		if action == "" {
+			continue
+		}
+		summary.Outputs = append(summary.Outputs, OutputChangeSummary{
+			Name:   name,
+			Action: action,
+			Before: maskValue(change.Before, change.BeforeSensitive, nil),
+			After:  maskValue(change.After, change.AfterSensitive, change.AfterUnknown),
+		})
+	}
+
+	return summary
+}
+
+func (s PlanSummary) HasChanges() bool {
+	return len(s.Groups) > 0 || len(s.Outputs) > 0
+}
+
+func (s PlanSummary) Json() (string, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal plan summary: %w", err)
+	}
+	return string(data), nil
+}
+
+func (s PlanSummary) Markdown() string {
+	builder := strings.Builder{}
+
+	title := "Terraform plan"
+	if s.Deployment != "" {
+		title = fmt.Sprintf("%s for `%s`", title, s.Deployment)
+	}
+	if s.Account != "" {
+		title = fmt.Sprintf("%s in `%s`", title, s.Account)
+	}
+	if s.AccountId != "" {
+		title = fmt.Sprintf("%s (%s)", title, s.AccountId)
+	}
+	builder.WriteString(fmt.Sprintf("### %s\n\n", title))
+
+	if !s.HasChanges() {
+		builder.WriteString("No changes.\n")
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("**%d** to create, **%d** to update, **%d** to replace, **%d** to destroy\n\n",
+		s.Counts["create"], s.Counts["update"], s.Counts["replace"], s.Counts["destroy"]))
+
+	for _, group := range s.Groups {
+		if group.Module != "" {
+			builder.WriteString(fmt.Sprintf("#### `%s` in `%s`\n\n", group.Type, group.Module))
+		} else {
+			builder.WriteString(fmt.Sprintf("#### `%s`\n\n", group.Type))
+		}
+
+		for _, resource := range group.Resources {
+			builder.WriteString(fmt.Sprintf("- **%s** `%s`\n", resource.Action, resource.Address))
+			for _, attribute := range resource.Attributes {
+				builder.WriteString(fmt.Sprintf("  - `%s`: %s → %s\n", attribute.Name, markdownValue(attribute.Before), markdownValue(attribute.After)))
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(s.Outputs) > 0 {
+		builder.WriteString("#### Outputs\n\n")
+		for _, output := range s.Outputs {
+			builder.WriteString(fmt.Sprintf("- **%s** `%s`: %s → %s\n", output.Action, output.Name, markdownValue(output.Before), markdownValue(output.After)))
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+func markdownValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "_null_"
+	case string:
+		if v == sensitiveValue || v == unknownValue {
+			return "_" + v + "_"
+		}
+		return fmt.Sprintf("`%s`", strings.ReplaceAll(v, "`", "'"))
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("`%v`", v)
+		}
+		return fmt.Sprintf("`%s`", data)
+	}
+}
+
+func actionName(actions tfjson.Actions) string {
+	switch {
+	case actions.Replace():
+		return "replace"
+	case actions.Create():
+		return "create"
+	case actions.Update():
+		return "update"
+	case actions.Delete():
+		return "destroy"
+	default:
+		return ""
+	}
+}
+
+// attributeChanges compares the flattened attributes before and after the change
+func attributeChanges(change *tfjson.Change) []AttributeChange {
+	before := map[string]flatAttribute{}
+	flatten(nil, change.Before, before)
+	after := map[string]flatAttribute{}
+	flatten(nil, change.After, after)
+	unknown := map[string]flatAttribute{}
+	flatten(nil, change.AfterUnknown, unknown)
+
+	paths := map[string][]string{}
+	for name, attribute := range before {
+		paths[name] = attribute.path
+	}
+	for name, attribute := range after {
+		paths[name] = attribute.path
+	}
+	for name, attribute := range unknown {
+		if attribute.value == true {
+			paths[name] = attribute.path
+		}
+	}
+
+	var changes []AttributeChange
+	for name, path := range paths {
+		beforeValue := before[name].value
+		afterValue := after[name].value
+		isUnknown := isMarked(change.AfterUnknown, path)
+		if !isUnknown && reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+
+		attribute := AttributeChange{Name: name, Before: beforeValue, After: afterValue}
+		if isMarked(change.BeforeSensitive, path) {
+			attribute.Before = sensitiveValue
+		}
+		if isMarked(change.AfterSensitive, path) {
+			attribute.After = sensitiveValue
+		} else if isUnknown {
+			attribute.After = unknownValue
+		}
+		changes = append(changes, attribute)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// flatAttribute is a leaf value and the keys and indexes leading to it
+type flatAttribute struct {
+	path  []string
+	value interface{}
+}
+
+// flatten converts nested objects and lists into leaf values named by their path (see attributeName)
+func flatten(path []string, value interface{}, result map[string]flatAttribute) {
+	child := func(key string) []string {
+		return append(slices.Clone(path), key)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && len(path) > 0 {
+			result[attributeName(path)] = flatAttribute{path: path, value: v}
+		}
+		for key, childValue := range v {
+			flatten(child(key), childValue, result)
+		}
+	case []interface{}:
+		if len(v) == 0 && len(path) > 0 {
+			result[attributeName(path)] = flatAttribute{path: path, value: v}
+		}
+		for i, childValue := range v {
+			flatten(child(strconv.Itoa(i)), childValue, result)
+		}
+	case nil:
+		if len(path) > 0 {
+			result[attributeName(path)] = flatAttribute{path: path, value: nil}
+		}
+	default:
+		result[attributeName(path)] = flatAttribute{path: path, value: v}
+	}
+}
+
+// attributeName joins the path with dots. Keys which can not be told apart from a path, e.g. `kubernetes.io/role`, are
+// quoted like `tags["kubernetes.io/role"]`.
+func attributeName(path []string) string {
+	builder := strings.Builder{}
+	for i, key := range path {
+		switch {
+		case strings.ContainsAny(key, `.[]"`):
+			builder.WriteString("[" + strconv.Quote(key) + "]")
+		case i > 0:
+			builder.WriteString("." + key)
+		default:
+			builder.WriteString(key)
+		}
+	}
+	return builder.String()
+}
+
+// isMarked checks whether the attribute or any of its parents is marked, e.g. as sensitive or unknown
+func isMarked(marks interface{}, path []string) bool {
+	current := marks
+	for _, key := range path {
+		if current == true {
+			return true
+		}
+		switch v := current.(type) {
+		case map[string]interface{}:
+			current = v[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index >= len(v) {
+				return false
+			}
+			current = v[index]
+		default:
+			return false
+		}
+	}
+	return current == true
+}
+
+func maskValue(value interface{}, sensitive interface{}, unknown interface{}) interface{} {
+	if sensitive == true {
+		return sensitiveValue
+	}
+	if unknown == true {
+		return unknownValue
+	}
+	return value
+}
@@ -0,0 +1,118 @@
+package terraform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestNewPlanSummary(t *testing.T) {
+	plan := &tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			{
+				Address: "aws_db_instance.main",
+				Mode:    tfjson.ManagedResourceMode,
+				Type:    "aws_db_instance",
+				Change: &tfjson.Change{
+					Actions:         tfjson.Actions{tfjson.ActionUpdate},
+					Before:          map[string]interface{}{"password": "old", "size": "small", "name": "db"},
+					After:           map[string]interface{}{"password": "new", "size": "large", "name": "db"},
+					BeforeSensitive: map[string]interface{}{"password": true},
+					AfterSensitive:  map[string]interface{}{"password": true},
+				},
+			},
+			{
+				Address:       "module.network.aws_vpc.this",
+				ModuleAddress: "module.network",
+				Mode:          tfjson.ManagedResourceMode,
+				Type:          "aws_vpc",
+				Change: &tfjson.Change{
+					Actions:      tfjson.Actions{tfjson.ActionCreate},
+					After:        map[string]interface{}{"cidr_block": "10.0.0.0/16", "tags": map[string]interface{}{"env": "dev"}},
+					AfterUnknown: map[string]interface{}{"id": true},
+				},
+			},
+			{
+				Address: "aws_s3_bucket.unchanged",
+				Mode:    tfjson.ManagedResourceMode,
+				Type:    "aws_s3_bucket",
+				Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionNoop}},
+			},
+		},
+		OutputChanges: map[string]*tfjson.Change{
+			"secret": {Actions: tfjson.Actions{tfjson.ActionCreate}, After: "value", AfterSensitive: true},
+		},
+	}
+
+	summary := NewPlanSummary(plan, ExecutionOptions{AccountName: "dev"})
+
+	expectedCounts := map[string]int{"create": 1, "update": 1, "replace": 0, "destroy": 0}
+	if !reflect.DeepEqual(summary.Counts, expectedCounts) {
+		t.Errorf("Counts = %v, want %v", summary.Counts, expectedCounts)
+	}
+
+	expectedGroups := []ResourceGroup{
+		{
+			Type: "aws_db_instance",
+			Resources: []ResourceChangeSummary{
+				{
+					Address: "aws_db_instance.main",
+					Action:  "update",
+					Attributes: []AttributeChange{
+						{Name: "password", Before: sensitiveValue, After: sensitiveValue},
+						{Name: "size", Before: "small", After: "large"},
+					},
+				},
+			},
+		},
+		{
+			Module: "module.network",
+			Type:   "aws_vpc",
+			Resources: []ResourceChangeSummary{
+				{
+					Address: "module.network.aws_vpc.this",
+					Action:  "create",
+					Attributes: []AttributeChange{
+						{Name: "cidr_block", After: "10.0.0.0/16"},
+						{Name: "id", After: unknownValue},
+						{Name: "tags.env", After: "dev"},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(summary.Groups, expectedGroups) {
+		t.Errorf("Groups = %+v, want %+v", summary.Groups, expectedGroups)
+	}
+
+	expectedOutputs := []OutputChangeSummary{{Name: "secret", Action: "create", After: sensitiveValue}}
+	if !reflect.DeepEqual(summary.Outputs, expectedOutputs) {
+		t.Errorf("Outputs = %+v, want %+v", summary.Outputs, expectedOutputs)
+	}
+
+	markdown := summary.Markdown()
+	if strings.Contains(markdown, "new") || strings.Contains(markdown, "value") {
+		t.Errorf("Markdown() contains sensitive values:\n%s", markdown)
+	}
+}
+
+func TestAttributeChangesDottedKeys(t *testing.T) {
+	change := &tfjson.Change{
+		Actions: tfjson.Actions{tfjson.ActionUpdate},
+		Before:  map[string]interface{}{"tags": map[string]interface{}{"kubernetes.io/role": "old", "env": "dev"}},
+		After:   map[string]interface{}{"tags": map[string]interface{}{"kubernetes.io/role": "new", "env": "prod"}},
+		AfterSensitive: map[string]interface{}{
+			"tags": map[string]interface{}{"kubernetes.io/role": true},
+		},
+	}
+
+	expected := []AttributeChange{
+		{Name: "tags.env", Before: "dev", After: "prod"},
+		{Name: `tags["kubernetes.io/role"]`, Before: "old", After: sensitiveValue},
+	}
+	if got := attributeChanges(change); !reflect.DeepEqual(got, expected) {
+		t.Errorf("attributeChanges() = %v, want %v", got, expected)
+	}
+}
@@ -0,0 +1,171 @@
+package terraform
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type StackStatus string
+
+const (
+	StackStatusSuccess StackStatus = "success"
+	StackStatusFailure StackStatus = "failure"
+	StackStatusSkipped StackStatus = "skipped"
+)
+
+type StackResult struct {
+	Deployment Deployment
+	Status     StackStatus
+	Err        error
+}
+
+// Stack is a set of deployments ordered by their dependencies
+type Stack struct {
+	deployments map[string]Deployment
+	// names keeps the discovery order, so runs are reproducible
+	names []string
+}
+
+// NewStack validates the dependencies of the deployments. Unknown dependencies and cycles are reported as error.
+func NewStack(deployments []Deployment) (*Stack, error) {
+	stack := &Stack{deployments: map[string]Deployment{}}
+	for _, deployment := range deployments {
+		if existing, found := stack.deployments[deployment.Name]; found {
+			return nil, errors.Errorf("the deployment name %q is used by %s and %s", deployment.Name, existing.Directory, deployment.Directory)
+		}
+		stack.deployments[deployment.Name] = deployment
+		stack.names = append(stack.names, deployment.Name)
+	}
+
+	for _, deployment := range deployments {
+		for _, dependency := range deployment.DependsOn {
+			if _, found := stack.deployments[dependency]; !found {
+				return nil, errors.Errorf("deployment %q depends on unknown deployment %q", deployment.Name, dependency)
+			}
+		}
+	}
+
+	if _, err := stack.Order(); err != nil {
+		return nil, err
+	}
+	return stack, nil
+}
+
+// Order returns the deployments grouped in levels. Deployments of one level only depend on deployments of previous levels.
+func (s *Stack) Order() ([][]Deployment, error) {
+	level := map[string]int{}
+	visiting := map[string]bool{}
+
+	var visit func(name string, path []string) (int, error)
+	visit = func(name string, path []string) (int, error) {
+		if l, found := level[name]; found {
+			return l, nil
+		}
+		if visiting[name] {
+			return 0, errors.Errorf("dependency cycle detected: %s", strings.Join(append(path, name), " -> "))
+		}
+		visiting[name] = true
+
+		l := 0
+		path = append(path[:len(path):len(path)], name)
+		for _, dependency := range s.deployments[name].DependsOn {
+			dependencyLevel, err := visit(dependency, path)
+			if err != nil {
+				return 0, err
+			}
+			l = max(l, dependencyLevel+1)
+		}
+
+		visiting[name] = false
+		level[name] = l
+		return l, nil
+	}
+
+	var levels [][]Deployment
+	for _, name := range s.names {
+		l, err := visit(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		for len(levels) <= l {
+			levels = append(levels, nil)
+		}
+		levels[l] = append(levels[l], s.deployments[name])
+	}
+	return levels, nil
+}
+
+// Run executes the action for each deployment as soon as all its dependencies succeeded. With reverse, a deployment
+// waits for all deployments depending on it instead (e.g. for destroying). Deployments whose prerequisites failed are skipped.
+func (s *Stack) Run(reverse bool, parallelism int, action func(deployment Deployment) error) []StackResult {
+	prerequisites := map[string][]string{}
+	for _, name := range s.names {
+		for _, dependency := range s.deployments[name].DependsOn {
+			if reverse {
+				prerequisites[dependency] = append(prerequisites[dependency], name)
+			} else {
+				prerequisites[name] = append(prerequisites[name], dependency)
+			}
+		}
+	}
+
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	semaphore := make(chan struct{}, parallelism)
+
+	done := map[string]chan struct{}{}
+	results := map[string]*StackResult{}
+	for _, name := range s.names {
+		done[name] = make(chan struct{})
+		results[name] = &StackResult{Deployment: s.deployments[name]}
+	}
+
+	var waitGroup sync.WaitGroup
+	for _, name := range s.names {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			defer close(done[name])
+			result := results[name]
+
+			for _, prerequisite := range prerequisites[name] {
+				<-done[prerequisite]
+				if results[prerequisite].Status != StackStatusSuccess {
+					result.Status = StackStatusSkipped
+					result.Err = fmt.Errorf("%s did not succeed", prerequisite)
+					return
+				}
+			}
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if err := action(result.Deployment); err != nil {
+				result.Status = StackStatusFailure
+				result.Err = err
+				return
+			}
+			result.Status = StackStatusSuccess
+		}()
+	}
+	waitGroup.Wait()
+
+	// the order was validated when creating the stack
+	levels, _ := s.Order()
+	if reverse {
+		slices.Reverse(levels)
+	}
+
+	ordered := make([]StackResult, 0, len(s.names))
+	for _, level := range levels {
+		for _, deployment := range level {
+			ordered = append(ordered, *results[deployment.Name])
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,125 @@
+package terraform
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestStack_Order(t *testing.T) {
+	stack, err := NewStack([]Deployment{
+		{Name: "app", DependsOn: []string{"data", "network"}},
+		{Name: "data", DependsOn: []string{"network"}},
+		{Name: "network"},
+		{Name: "dns"},
+	})
+	if err != nil {
+		t.Fatalf("NewStack() error = %v", err)
+	}
+
+	levels, err := stack.Order()
+	if err != nil {
+		t.Fatalf("Order() error = %v", err)
+	}
+
+	var names [][]string
+	for _, level := range levels {
+		var levelNames []string
+		for _, deployment := range level {
+			levelNames = append(levelNames, deployment.Name)
+		}
+		names = append(names, levelNames)
+	}
+
+	expected := [][]string{{"network", "dns"}, {"data"}, {"app"}}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Order() = %v, want %v", names, expected)
+	}
+}
+
+func TestNewStack_InvalidDependencies(t *testing.T) {
+	tests := []struct {
+		name        string
+		deployments []Deployment
+	}{
+		{
+			name:        "Unknown dependency",
+			deployments: []Deployment{{Name: "app", DependsOn: []string{"network"}}},
+		},
+		{
+			name: "Cycle",
+			deployments: []Deployment{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"c"}},
+				{Name: "c", DependsOn: []string{"a"}},
+			},
+		},
+		{
+			name:        "Duplicate name",
+			deployments: []Deployment{{Name: "a", Directory: "x/a"}, {Name: "a", Directory: "y/a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewStack(tt.deployments); err == nil {
+				t.Errorf("NewStack() expected an error")
+			}
+		})
+	}
+}
+
+func TestStack_Run(t *testing.T) {
+	stack, err := NewStack([]Deployment{
+		{Name: "network"},
+		{Name: "data", DependsOn: []string{"network"}},
+		{Name: "app", DependsOn: []string{"data"}},
+		{Name: "dns"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Skips dependents of failed deployments", func(t *testing.T) {
+		results := stack.Run(false, 2, func(deployment Deployment) error {
+			if deployment.Name == "data" {
+				return fmt.Errorf("failed")
+			}
+			return nil
+		})
+
+		statuses := map[string]StackStatus{}
+		for _, result := range results {
+			statuses[result.Deployment.Name] = result.Status
+		}
+		expected := map[string]StackStatus{
+			"network": StackStatusSuccess,
+			"dns":     StackStatusSuccess,
+			"data":    StackStatusFailure,
+			"app":     StackStatusSkipped,
+		}
+		if !reflect.DeepEqual(statuses, expected) {
+			t.Errorf("Run() statuses = %v, want %v", statuses, expected)
+		}
+	})
+
+	t.Run("Runs in reverse order", func(t *testing.T) {
+		var mutex sync.Mutex
+		var order []string
+		stack.Run(true, 1, func(deployment Deployment) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			order = append(order, deployment.Name)
+			return nil
+		})
+
+		position := map[string]int{}
+		for i, name := range order {
+			position[name] = i
+		}
+		if position["app"] > position["data"] || position["data"] > position["network"] {
+			t.Errorf("Run() order = %v, dependents must run before their dependencies", order)
+		}
+	})
+}
@@ -1,38 +1,72 @@
 package terraform
 
 import (
+	"context"
 	"os"
 	"os/exec"
 
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/apex/log"
 	"github.com/hashicorp/terraform-exec/tfexec"
 	"github.com/pkg/errors"
 )
 
 type ITerraformProvider interface {
-	Terraform(workDir string) (*tfexec.Terraform, error)
+	Terraform(workDir string, cfg *config.TerraformConfig) (*tfexec.Terraform, error)
 }
 
 type TerraformProvider struct {
+	// offline only uses installed Terraform versions regardless of the config
+	offline bool
 }
 
-func NewTerraformProvider() ITerraformProvider {
-	return &TerraformProvider{}
+func NewTerraformProvider(offline bool) ITerraformProvider {
+	return &TerraformProvider{offline: offline}
 }
 
-func (p *TerraformProvider) Terraform(workDir string) (*tfexec.Terraform, error) {
-	execPath, err := exec.LookPath("terraform")
+// Terraform returns the engine of the config. For Terraform, the version required by the config is installed if
+// necessary; without a required version and for OpenTofu, the executable in the PATH is used.
+func (p *TerraformProvider) Terraform(workDir string, cfg *config.TerraformConfig) (*tfexec.Terraform, error) {
+	execPath, err := p.execPath(cfg)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not find terraform executable in PATH")
-	}
-	if execPath == "" {
-		return nil, errors.Errorf("could not find Terraform executable")
+		return nil, err
 	}
 	tf, err := tfexec.NewTerraform(workDir, execPath)
 	if err != nil {
-		return nil, errors.Wrap(err, "error finding terraform cli")
+		return nil, errors.Wrapf(err, "error finding %s cli", cfg.EngineName())
 	}
 
 	tf.SetStdout(os.Stdout)
 
+	if v, _, err := tf.Version(context.Background(), true); err != nil {
+		log.WithError(err).Warnf("failed to get the version of %s", execPath)
+	} else {
+		log.Infof("using %s %s (%s)", cfg.EngineName(), v, execPath)
+	}
+
 	return tf, nil
 }
+
+func (p *TerraformProvider) execPath(cfg *config.TerraformConfig) (string, error) {
+	engine := cfg.EngineName()
+	switch engine {
+	case config.EngineTerraform:
+		if cfg.TerraformVersion != "" {
+			install := cfg.Install
+			install.Offline = install.Offline || p.offline
+			return newInstaller(install).ExecPath(cfg.TerraformVersion)
+		}
+	case config.EngineTofu:
+	default:
+		return "", errors.Errorf("unknown engine %q; use %q or %q", engine, config.EngineTerraform, config.EngineTofu)
+	}
+
+	execPath, err := exec.LookPath(engine)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not find %s executable in PATH", engine)
+	}
+	if execPath == "" {
+		return "", errors.Errorf("could not find %s executable", engine)
+	}
+	return execPath, nil
+}
@@ -0,0 +1,156 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/pkg/errors"
+)
+
+var accountIdPattern = regexp.MustCompile(`^\d{12}$`)
+
+// maximum number of suggestions for unknown account names
+const maxAccountSuggestions = 3
+
+// aliasingAws resolves the account aliases of a profile before calling the strategy
+type aliasingAws struct {
+	IAws
+	accounts map[string]config.Account
+}
+
+func newAliasingAws(strategy IAws, accounts map[string]config.Account) IAws {
+	if len(accounts) == 0 {
+		return strategy
+	}
+	return &aliasingAws{IAws: strategy, accounts: accounts}
+}
+
+func (a *aliasingAws) FindAccountId(alias string) (string, error) {
+	account, found := a.account(alias)
+	if found && account.Id != "" {
+		return account.Id, nil
+	}
+	return a.IAws.FindAccountId(a.accountName(alias, account, found))
+}
+
+func (a *aliasingAws) AssumeRole(role, accountName string) (*AwsAccountAccess, error) {
+	account, found := a.account(accountName)
+	access, err := a.IAws.AssumeRole(a.role(role, account), a.target(accountName, account, found))
+	if err != nil {
+		return nil, err
+	}
+	access.Region = account.Region
+	return access, nil
+}
+
+func (a *aliasingAws) AssumeRoleWithMfa(role, accountName string) (*AwsAccountAccess, error) {
+	account, found := a.account(accountName)
+	access, err := a.IAws.AssumeRoleWithMfa(a.role(role, account), a.target(accountName, account, found))
+	if err != nil {
+		return nil, err
+	}
+	access.Region = account.Region
+	return access, nil
+}
+
+func (a *aliasingAws) account(alias string) (config.Account, bool) {
+	for name, account := range a.accounts {
+		if strings.EqualFold(name, alias) {
+			return account, true
+		}
+	}
+	return config.Account{}, false
+}
+
+// target returns the pinned account id, so the strategy skips the lookup, or the name of the account
+func (a *aliasingAws) target(alias string, account config.Account, found bool) string {
+	if found && account.Id != "" {
+		return account.Id
+	}
+	return a.accountName(alias, account, found)
+}
+
+func (a *aliasingAws) accountName(alias string, account config.Account, found bool) string {
+	if found && account.Name != "" {
+		return account.Name
+	}
+	return alias
+}
+
+func (a *aliasingAws) role(role string, account config.Account) string {
+	if role == "" {
+		return account.Role
+	}
+	return role
+}
+
+// isAccountId reports whether the account is given by its id instead of its name
+func isAccountId(account string) bool {
+	return accountIdPattern.MatchString(account)
+}
+
+// accountNotFound creates an error for an unknown account suggesting the most similar account names
+func accountNotFound(name string, candidates []string) error {
+	suggestions := suggestAccounts(name, candidates)
+	if len(suggestions) == 0 {
+		return errors.Errorf("no aws account %q found", name)
+	}
+	return fmt.Errorf("no aws account %q found; did you mean %s?", name, strings.Join(suggestions, ", "))
+}
+
+// suggestAccounts returns the candidates containing the name or with a small edit distance to it, most similar first
+func suggestAccounts(name string, candidates []string) []string {
+	name = strings.ToLower(name)
+	maxDistance := max(2, len(name)/3)
+
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	var suggestions []suggestion
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		distance := levenshtein(name, lower)
+		if strings.Contains(lower, name) || strings.Contains(name, lower) {
+			distance = min(distance, maxDistance)
+		}
+		if distance <= maxDistance {
+			suggestions = append(suggestions, suggestion{name: candidate, distance: distance})
+		}
+	}
+
+	slices.SortStableFunc(suggestions, func(a, b suggestion) int {
+		return a.distance - b.distance
+	})
+
+	result := make([]string, 0, maxAccountSuggestions)
+	for _, s := range suggestions[:min(len(suggestions), maxAccountSuggestions)] {
+		result = append(result, fmt.Sprintf("%q", s.name))
+	}
+	return result
+}
+
+func levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/IronFE/iron.cli/util/config"
+)
+
+func TestSuggestAccounts(t *testing.T) {
+	candidates := []string{"acme-workload-payments-prod", "acme-workload-payments-dev", "acme-shared-network", "sandbox"}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "sandbx", want: []string{`"sandbox"`}},
+		{name: "payments-prod", want: []string{`"acme-workload-payments-prod"`}},
+		{name: "acme-workload-payments-prd", want: []string{`"acme-workload-payments-prod"`, `"acme-workload-payments-dev"`}},
+		{name: "billing", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestAccounts(tt.name, candidates); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("suggestAccounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAliasingTarget(t *testing.T) {
+	aliasing := &aliasingAws{accounts: map[string]config.Account{
+		"pay-prod": {Name: "acme-workload-payments-prod", Role: "Deployer"},
+		"net":      {Id: "123456789012"},
+	}}
+
+	tests := []struct {
+		alias      string
+		role       string
+		wantTarget string
+		wantRole   string
+	}{
+		{alias: "Pay-Prod", wantTarget: "acme-workload-payments-prod", wantRole: "Deployer"},
+		{alias: "pay-prod", role: "Admin", wantTarget: "acme-workload-payments-prod", wantRole: "Admin"},
+		{alias: "net", wantTarget: "123456789012"},
+		{alias: "sandbox", role: "Admin", wantTarget: "sandbox", wantRole: "Admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			account, found := aliasing.account(tt.alias)
+			if got := aliasing.target(tt.alias, account, found); got != tt.wantTarget {
+				t.Errorf("target() = %v, want %v", got, tt.wantTarget)
+			}
+			if got := aliasing.role(tt.role, account); got != tt.wantRole {
+				t.Errorf("role() = %v, want %v", got, tt.wantRole)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/IronFE/iron.cli/util/config"
+	"gopkg.in/yaml.v3"
+)
+
+type AccountInfo struct {
+	Id    string   `json:"id" yaml:"id"`
+	Name  string   `json:"name" yaml:"name"`
+	Email string   `json:"email,omitempty" yaml:"email,omitempty"`
+	Roles []string `json:"roles,omitempty" yaml:"roles,omitempty"`
+}
+
+type cachedAccounts struct {
+	UpdatedAt time.Time     `yaml:"updatedAt"`
+	Accounts  []AccountInfo `yaml:"accounts"`
+}
+
+// AccountCache stores the accounts accessible by a profile, e.g. for shell completion
+type AccountCache struct {
+	dir string
+}
+
+func NewAccountCache() *AccountCache {
+	return &AccountCache{dir: filepath.Join(config.BaseDir(), "accounts")}
+}
+
+// Get returns the cached accounts of the profile and the time they were cached
+func (c *AccountCache) Get(profile string) ([]AccountInfo, time.Time, error) {
+	content, err := os.ReadFile(c.filePath(profile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, time.Time{}, nil
+		}
+		return nil, time.Time{}, fmt.Errorf("failed to read account cache: %w", err)
+	}
+
+	cached := cachedAccounts{}
+	if err = yaml.Unmarshal(content, &cached); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to parse account cache: %w", err)
+	}
+	return cached.Accounts, cached.UpdatedAt, nil
+}
+
+func (c *AccountCache) Put(profile string, accounts []AccountInfo) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create account cache folder: %w", err)
+	}
+
+	data, err := yaml.Marshal(cachedAccounts{UpdatedAt: time.Now(), Accounts: accounts})
+	if err != nil {
+		return fmt.Errorf("failed to marshal accounts: %w", err)
+	}
+
+	if err = os.WriteFile(c.filePath(profile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write account cache: %w", err)
+	}
+	return nil
+}
+
+func (c *AccountCache) filePath(profile string) string {
+	return filepath.Join(c.dir, cacheFileNameInvalidChars.ReplaceAllString(profile, "-")+".yaml")
+}
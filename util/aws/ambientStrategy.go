@@ -0,0 +1,166 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	ironConfig "github.com/IronFE/iron.cli/util/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/pkg/errors"
+)
+
+// ambientStrategy uses credentials provided outside of iron (AWS CLI profiles, web identity tokens or the environment)
+// as base for assuming roles. Without assuming a role, the base credentials are used directly.
+type ambientStrategy struct {
+	*awsAbstraction
+	name  string
+	login func(options LoginOptions) error
+}
+
+func newAwsProfileStrategy(profile ironConfig.Profile, session SessionOptions) (IAws, error) {
+	if profile.AwsProfile == nil || profile.AwsProfile.ProfileName == "" {
+		return nil, errors.Errorf("the profile has no `awsProfile.profileName`")
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithSharedConfigProfile(profile.AwsProfile.ProfileName),
+		config.WithRegion(profile.DefaultRegion))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS profile %q: %w", profile.AwsProfile.ProfileName, err)
+	}
+
+	return &ambientStrategy{
+		awsAbstraction: newAwsAbstraction(cfg, profile.Name, profile.DefaultRegion, &mfaCodes{}, session),
+		name:           "awsProfile",
+		login: func(options LoginOptions) error {
+			return awsSsoLogin(profile.AwsProfile.ProfileName, options)
+		},
+	}, nil
+}
+
+func newWebIdentityStrategy(profile ironConfig.Profile, session SessionOptions) (IAws, error) {
+	webIdentity := ironConfig.WebIdentity{}
+	if profile.WebIdentity != nil {
+		webIdentity = *profile.WebIdentity
+	}
+
+	roleArn := webIdentity.RoleArn
+	if roleArn == "" {
+		roleArn = os.Getenv("AWS_ROLE_ARN")
+	}
+	if roleArn == "" {
+		return nil, errors.Errorf("no role configured; set `webIdentity.roleArn` or AWS_ROLE_ARN")
+	}
+
+	var token stscreds.IdentityTokenRetriever
+	switch {
+	case webIdentity.TokenEnv != "":
+		token = envIdentityToken(webIdentity.TokenEnv)
+	case webIdentity.TokenFile != "":
+		token = stscreds.IdentityTokenFile(webIdentity.TokenFile)
+	case os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") != "":
+		token = stscreds.IdentityTokenFile(os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"))
+	default:
+		return nil, errors.Errorf("no token configured; set `webIdentity.tokenFile`, `webIdentity.tokenEnv` or AWS_WEB_IDENTITY_TOKEN_FILE")
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(profile.DefaultRegion))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	provider := stscreds.NewWebIdentityRoleProvider(sts.NewFromConfig(cfg), roleArn, token, func(options *stscreds.WebIdentityRoleOptions) {
+		options.RoleSessionName = session.RoleSessionName()
+		options.Duration = session.Duration
+	})
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+
+	return &ambientStrategy{
+		awsAbstraction: newAwsAbstraction(cfg, profile.Name, profile.DefaultRegion, &mfaCodes{}, session),
+		name:           "webIdentity",
+	}, nil
+}
+
+func newEnvironmentStrategy(profile ironConfig.Profile, session SessionOptions) (IAws, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(profile.DefaultRegion))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &ambientStrategy{
+		awsAbstraction: newAwsAbstraction(cfg, profile.Name, profile.DefaultRegion, &mfaCodes{}, session),
+		name:           "environment",
+	}, nil
+}
+
+// SessionToken returns the base credentials, as temporary credentials can not request a session token
+func (a *ambientStrategy) SessionToken(_ time.Duration) (*AwsAccountAccess, error) {
+	creds, err := a.config.Credentials.Retrieve(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve credentials: %w", err)
+	}
+
+	accountId := creds.AccountID
+	if accountId == "" {
+		identity, err := sts.NewFromConfig(a.config).GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get caller identity: %w", err)
+		}
+		accountId = aws.ToString(identity.Account)
+	}
+
+	access := &AwsAccountAccess{
+		AccountId:    accountId,
+		AccessKeyId:  creds.AccessKeyID,
+		SecretKey:    creds.SecretAccessKey,
+		SessionToken: creds.SessionToken,
+	}
+	if creds.CanExpire {
+		access.Expiration = creds.Expires
+	}
+	return access, nil
+}
+
+func (a *ambientStrategy) SessionTokenRequiresMfa() bool {
+	return false
+}
+
+func (a *ambientStrategy) Login(options LoginOptions) error {
+	if a.login == nil {
+		return errors.Errorf("a login is not supported by the %s strategy", a.name)
+	}
+	return a.login(options)
+}
+
+// awsSsoLogin logs in the AWS CLI profile
+func awsSsoLogin(profileName string, options LoginOptions) error {
+	args := []string{"sso", "login", "--profile", profileName}
+	if options.NoBrowser {
+		args = append(args, "--no-browser")
+	}
+
+	cmd := exec.Command("aws", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run aws sso login: %w", err)
+	}
+	return nil
+}
+
+type envIdentityToken string
+
+func (e envIdentityToken) GetIdentityToken() ([]byte, error) {
+	token := os.Getenv(string(e))
+	if token == "" {
+		return nil, errors.Errorf("the environment variable %s contains no token", string(e))
+	}
+	return []byte(token), nil
+}
@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"strings"
 	"time"
 
+	ironConfig "github.com/IronFE/iron.cli/util/config"
 	"github.com/apex/log"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
 	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+	"github.com/mdp/qrterminal/v3"
 	"github.com/pkg/browser"
 	"gopkg.in/yaml.v3"
 )
@@ -21,102 +25,288 @@ import (
 type fileCachedAuthProvider struct {
 	startUrl string
 	region   string
+	options  LoginOptions
+}
+
+type LoginOptions struct {
+	// NoBrowser only prints the link and code instead of opening a browser
+	NoBrowser bool
+	// QrCode additionally prints the link as QR code
+	QrCode bool
+	// Timeout limits the time to wait for the confirmation; the lifetime of the device code is used if it is shorter
+	Timeout time.Duration
 }
 
 type authFile struct {
 	SessionToken   string    `yaml:"sessionToken"`
 	ExpirationDate time.Time `yaml:"expirationDate"`
+	// the refresh token allows to renew the session token without user interaction
+	RefreshToken string `yaml:"refreshToken,omitempty"`
+	// the client registration is required for creating and refreshing tokens
+	ClientId             string    `yaml:"clientId,omitempty"`
+	ClientSecret         string    `yaml:"clientSecret,omitempty"`
+	ClientExpirationDate time.Time `yaml:"clientExpirationDate,omitempty"`
+}
+
+func (a authFile) hasValidToken() bool {
+	return a.SessionToken != "" && a.ExpirationDate.After(time.Now().Add(2*time.Second))
+}
+
+func (a authFile) hasValidClient() bool {
+	return a.ClientId != "" && a.ClientExpirationDate.After(time.Now().Add(time.Minute))
 }
 
 func (p *fileCachedAuthProvider) Auth() (string, error) {
 
-	token, err := p.readFromFile()
+	auth, err := p.readFromFile()
 	if err != nil {
 		log.WithError(err).Warn("could not get token from cache")
+		auth = authFile{}
+	}
 
-		output, err := p.createNewToken()
-		if err != nil {
-			return "", fmt.Errorf("failed to create new auth token: %w", err)
+	if auth.hasValidToken() {
+		return auth.SessionToken, nil
+	}
+
+	oidcClient, err := p.oidcClient()
+	if err != nil {
+		return "", err
+	}
+
+	if auth.RefreshToken != "" && auth.hasValidClient() {
+		output, err := p.refreshToken(oidcClient, auth)
+		if err == nil {
+			log.Debug("renewed token with refresh token")
+			return p.storeToken(auth, output), nil
 		}
+		log.WithError(err).Warn("failed to renew token; a new login is required")
+	}
+
+	return p.login(oidcClient, auth)
+}
+
+// Login performs a new device login regardless of any cached token
+func (p *fileCachedAuthProvider) Login(options LoginOptions) (string, error) {
+	p.options = options
+
+	auth, err := p.readFromFile()
+	if err != nil {
+		auth = authFile{}
+	}
 
-		expirationDate := time.Now().Add(time.Duration(output.ExpiresIn) * time.Second)
+	oidcClient, err := p.oidcClient()
+	if err != nil {
+		return "", err
+	}
 
-		if err = p.writeToFile(*output.AccessToken, expirationDate); err != nil {
-			log.WithError(err).Warn("failed to cache token in file")
+	return p.login(oidcClient, auth)
+}
+
+func (p *fileCachedAuthProvider) login(oidcClient *ssooidc.Client, auth authFile) (string, error) {
+	if !auth.hasValidClient() {
+		var err error
+		if auth, err = p.registerClient(oidcClient); err != nil {
+			return "", fmt.Errorf("failed to register client: %w", err)
 		}
+	}
+
+	output, err := p.createNewToken(oidcClient, auth)
+	if err != nil {
+		return "", fmt.Errorf("failed to create new auth token: %w", err)
+	}
+
+	return p.storeToken(auth, output), nil
+}
 
-		return *output.AccessToken, nil
+// storeToken caches the token of the output together with the client registration and returns the access token
+func (p *fileCachedAuthProvider) storeToken(auth authFile, output *ssooidc.CreateTokenOutput) string {
+	auth.SessionToken = aws.ToString(output.AccessToken)
+	auth.ExpirationDate = time.Now().Add(time.Duration(output.ExpiresIn) * time.Second)
+	if output.RefreshToken != nil {
+		auth.RefreshToken = *output.RefreshToken
+	}
 
+	if err := p.writeToFile(auth); err != nil {
+		log.WithError(err).Warn("failed to cache token in file")
 	}
 
-	return token, err
+	return auth.SessionToken
 }
 
-func (p *fileCachedAuthProvider) createNewToken() (*ssooidc.CreateTokenOutput, error) {
-	// code based on https://gist.github.com/ayubmalik/5b5b83b8153c0afdc1d31d5380001ff0
+func (p *fileCachedAuthProvider) oidcClient() (*ssooidc.Client, error) {
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithDefaultRegion(p.region))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create default config: %w", err)
 	}
 
-	// create SSO oidcClient client to trigger login flow
-	oidcClient := ssooidc.NewFromConfig(cfg)
+	return ssooidc.NewFromConfig(cfg), nil
+}
 
-	// register your client which is triggering the login flow
+func (p *fileCachedAuthProvider) registerClient(oidcClient *ssooidc.Client) (authFile, error) {
+	// the scope is required for getting a refresh token
 	register, err := oidcClient.RegisterClient(context.Background(), &ssooidc.RegisterClientInput{
 		ClientName: aws.String("sso-iron-cli"),
 		ClientType: aws.String("public"),
+		Scopes:     []string{"sso:account:access"},
 	})
-
 	if err != nil {
-		return nil, err
+		return authFile{}, err
 	}
 
-	// authorize your device using the client registration response
-	deviceAuth, err := oidcClient.StartDeviceAuthorization(context.Background(), &ssooidc.StartDeviceAuthorizationInput{
-		ClientId:     register.ClientId,
-		ClientSecret: register.ClientSecret,
+	return authFile{
+		ClientId:             aws.ToString(register.ClientId),
+		ClientSecret:         aws.ToString(register.ClientSecret),
+		ClientExpirationDate: time.Unix(register.ClientSecretExpiresAt, 0),
+	}, nil
+}
+
+func (p *fileCachedAuthProvider) refreshToken(oidcClient *ssooidc.Client, auth authFile) (*ssooidc.CreateTokenOutput, error) {
+	return oidcClient.CreateToken(context.Background(), &ssooidc.CreateTokenInput{
+		ClientId:     aws.String(auth.ClientId),
+		ClientSecret: aws.String(auth.ClientSecret),
+		RefreshToken: aws.String(auth.RefreshToken),
+		GrantType:    aws.String("refresh_token"),
+	})
+}
+
+func (p *fileCachedAuthProvider) createNewToken(oidcClient *ssooidc.Client, auth authFile) (*ssooidc.CreateTokenOutput, error) {
+	// code based on https://gist.github.com/ayubmalik/5b5b83b8153c0afdc1d31d5380001ff0
+
+	// the login can be cancelled by the user with ctrl+c
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// authorize your device using the client registration
+	deviceAuth, err := oidcClient.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     aws.String(auth.ClientId),
+		ClientSecret: aws.String(auth.ClientSecret),
 		StartUrl:     aws.String(p.startUrl),
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	timeout := time.Duration(deviceAuth.ExpiresIn) * time.Second
+	if p.options.Timeout > 0 && p.options.Timeout < timeout {
+		timeout = p.options.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	url := aws.ToString(deviceAuth.VerificationUriComplete)
-	log.Infof("If your browser is not opened automatically, please open link:\n%v\n", url)
+	p.printVerification(url, aws.ToString(deviceAuth.VerificationUri), aws.ToString(deviceAuth.UserCode))
 
-	err = browser.OpenURL(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open brower with url %q: %w", url, err)
+	if !p.options.NoBrowser {
+		if err = browser.OpenURL(url); err != nil {
+			log.WithError(err).Warn("failed to open browser; please open the link manually")
+		}
 	}
 
+	interval := time.Duration(deviceAuth.Interval) * time.Second
 	for {
-		output, err := oidcClient.CreateToken(context.Background(), &ssooidc.CreateTokenInput{
-			ClientId:     register.ClientId,
-			ClientSecret: register.ClientSecret,
+		output, err := oidcClient.CreateToken(ctx, &ssooidc.CreateTokenInput{
+			ClientId:     aws.String(auth.ClientId),
+			ClientSecret: aws.String(auth.ClientSecret),
 			DeviceCode:   deviceAuth.DeviceCode,
 			GrantType:    aws.String("urn:ietf:params:oauth:grant-type:device_code"),
 		})
+		if err == nil {
+			return output, nil
+		}
+
+		var pending *types.AuthorizationPendingException
+		var slowDown *types.SlowDownException
+		var expired *types.ExpiredTokenException
+		var denied *types.AccessDeniedException
+		switch {
+		case ctx.Err() != nil:
+			return nil, loginAbortReason(ctx)
+		case errors.As(err, &pending):
+			log.Debug("Authorization pending...")
+		case errors.As(err, &slowDown):
+			// see https://datatracker.ietf.org/doc/html/rfc8628#section-3.5
+			interval += 5 * time.Second
+			log.Debugf("Polling too fast; increasing interval to %s", interval)
+		case errors.As(err, &expired):
+			return nil, fmt.Errorf("the login was not confirmed in time; please try again")
+		case errors.As(err, &denied):
+			return nil, fmt.Errorf("the login was denied")
+		default:
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, loginAbortReason(ctx)
+		case <-time.After(interval):
+		}
+	}
+}
+
+func loginAbortReason(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("the login timed out")
+	}
+	return fmt.Errorf("the login was cancelled")
+}
+
+// printVerification prints the information to confirm the login on any device to stderr
+func (p *fileCachedAuthProvider) printVerification(urlWithCode, url, userCode string) {
+	_, _ = fmt.Fprintf(os.Stderr, "To log in, open the following link and confirm the code %s:\n%s\n", userCode, urlWithCode)
+	_, _ = fmt.Fprintf(os.Stderr, "Alternatively, open %s and enter the code manually.\n", url)
+
+	if p.options.QrCode {
+		qrterminal.GenerateHalfBlock(urlWithCode, qrterminal.L, os.Stderr)
+	}
+}
+
+// Expiration returns the expiration date of the cached token without logging in
+func (p *fileCachedAuthProvider) Expiration() (time.Time, error) {
+	auth, err := p.readFromFile()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return auth.ExpirationDate, nil
+}
+
+// Logout revokes the cached token and deletes the cache file
+func (p *fileCachedAuthProvider) Logout() error {
+	auth, err := p.readFromFile()
+	if err != nil {
+		log.WithError(err).Debug("no cached token to revoke")
+		return nil
+	}
+
+	if auth.hasValidToken() {
+		cfg, err := config.LoadDefaultConfig(context.Background(), config.WithDefaultRegion(p.region))
 		if err != nil {
-			isPending := strings.Contains(err.Error(), "AuthorizationPendingException:")
-			if isPending {
-				log.Debug("Authorization pending...")
-				time.Sleep(time.Duration(deviceAuth.Interval) * time.Second)
-				continue
-			}
+			return fmt.Errorf("failed to create default config: %w", err)
 		}
 
-		return output, nil
+		_, err = sso.NewFromConfig(cfg).Logout(context.Background(), &sso.LogoutInput{
+			AccessToken: aws.String(auth.SessionToken),
+		})
+		if err != nil {
+			log.WithError(err).Warn("failed to revoke token")
+		}
 	}
+
+	filePath, err := p.filePath()
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(filePath); err != nil {
+		return fmt.Errorf("failed to delete auth file: %w", err)
+	}
+	return nil
 }
 
-func (p *fileCachedAuthProvider) writeToFile(token string, expirationDate time.Time) error {
+func (p *fileCachedAuthProvider) writeToFile(data authFile) error {
 	filePath, err := p.filePath()
 	if err != nil {
 		return err
 	}
 
-	data := authFile{SessionToken: token, ExpirationDate: expirationDate}
 	bytes, err := yaml.Marshal(&data)
 	if err != nil {
 		return fmt.Errorf("failed to marshal auth file data: %w", err)
@@ -129,33 +319,29 @@ func (p *fileCachedAuthProvider) writeToFile(token string, expirationDate time.T
 	return nil
 }
 
-func (p *fileCachedAuthProvider) readFromFile() (string, error) {
+func (p *fileCachedAuthProvider) readFromFile() (authFile, error) {
 
 	authFilePath, err := p.filePath()
 	if err != nil {
-		return "", err
+		return authFile{}, err
 	}
 
 	_, err = os.Stat(authFilePath)
 	if errors.Is(err, os.ErrNotExist) {
-		return "", fmt.Errorf("the auth file does not exist")
+		return authFile{}, fmt.Errorf("the auth file does not exist")
 	}
 
 	content, err := os.ReadFile(authFilePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read auth file: %w", err)
+		return authFile{}, fmt.Errorf("failed to read auth file: %w", err)
 	}
 
 	auth := authFile{}
 	if err = yaml.Unmarshal(content, &auth); err != nil {
-		return "", fmt.Errorf("could not parse auth file %q: %w", authFilePath, err)
+		return authFile{}, fmt.Errorf("could not parse auth file %q: %w", authFilePath, err)
 	}
 
-	if auth.ExpirationDate.After(time.Now().Add(2 * time.Second)) {
-		return auth.SessionToken, nil
-	}
-
-	return "", fmt.Errorf("token is expired")
+	return auth, nil
 }
 
 func (p *fileCachedAuthProvider) filePath() (string, error) {
@@ -164,8 +350,5 @@ func (p *fileCachedAuthProvider) filePath() (string, error) {
 		return "", fmt.Errorf("failed to parse start url %q: %w", p.startUrl, err)
 	}
 
-	home := os.Getenv("HOME")
-	baseFolder := filepath.Join(home, ".iron-cli")
-
-	return filepath.Join(baseFolder, fmt.Sprintf("%s.yaml", startUrl.Host)), nil
+	return filepath.Join(ironConfig.BaseDir(), fmt.Sprintf("%s.yaml", startUrl.Host)), nil
 }
@@ -0,0 +1,34 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+type CallerIdentity struct {
+	Account string
+	Arn     string
+	UserId  string
+}
+
+// GetCallerIdentity returns the identity the credentials belong to
+func GetCallerIdentity(access *AwsAccountAccess, region string) (CallerIdentity, error) {
+	cfg, err := CreateConfig(access, region)
+	if err != nil {
+		return CallerIdentity{}, fmt.Errorf("failed to load default config: %w", err)
+	}
+
+	output, err := sts.NewFromConfig(cfg).GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return CallerIdentity{}, fmt.Errorf("failed to get caller identity: %w", err)
+	}
+
+	return CallerIdentity{
+		Account: aws.ToString(output.Account),
+		Arn:     aws.ToString(output.Arn),
+		UserId:  aws.ToString(output.UserId),
+	}, nil
+}
@@ -0,0 +1,254 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/apex/log"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// credentials are renewed if they expire within this duration
+const credentialRenewalMargin = 5 * time.Minute
+
+const sessionTokenCacheKey = "session-token"
+
+var cacheFileNameInvalidChars = regexp.MustCompile("[^a-zA-Z0-9._-]")
+
+type CachedCredentials struct {
+	Profile      string    `yaml:"profile"`
+	Account      string    `yaml:"account"`
+	Role         string    `yaml:"role"`
+	AccountId    string    `yaml:"accountId"`
+	AccessKeyId  string    `yaml:"accessKeyId"`
+	SecretKey    string    `yaml:"secretKey"`
+	SessionToken string    `yaml:"sessionToken"`
+	SessionName  string    `yaml:"sessionName,omitempty"`
+	Variant      string    `yaml:"variant,omitempty"`
+	Expiration   time.Time `yaml:"expiration"`
+}
+
+func (c CachedCredentials) Access() *AwsAccountAccess {
+	return &AwsAccountAccess{
+		AccountId:    c.AccountId,
+		AccessKeyId:  c.AccessKeyId,
+		SecretKey:    c.SecretKey,
+		SessionToken: c.SessionToken,
+		SessionName:  c.SessionName,
+		Expiration:   c.Expiration,
+	}
+}
+
+// CredentialCache stores credentials of assumed roles on disk, one file per profile, account, role and variant
+type CredentialCache struct {
+	dir string
+}
+
+func NewCredentialCache() *CredentialCache {
+	return &CredentialCache{dir: filepath.Join(config.BaseDir(), "credentials")}
+}
+
+// Get returns cached credentials, which are valid for at least the renewal margin
+func (c *CredentialCache) Get(profile, account, role, variant string) (*AwsAccountAccess, bool) {
+	path := c.filePath(profile, account, role, variant)
+	cached, err := c.read(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.WithError(err).Warn("could not read cached credentials")
+		}
+		return nil, false
+	}
+
+	if cached.Expiration.Before(time.Now().Add(credentialRenewalMargin)) {
+		return nil, false
+	}
+
+	log.Debugf("using cached credentials for role %q in %q (valid until %s)", role, account, cached.Expiration.Format(time.RFC3339))
+	return cached.Access(), true
+}
+
+func (c *CredentialCache) Put(profile, account, role, variant string, access *AwsAccountAccess) error {
+	if access.Expiration.IsZero() {
+		return nil
+	}
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create credential cache folder: %w", err)
+	}
+
+	data, err := yaml.Marshal(CachedCredentials{
+		Profile:      profile,
+		Account:      account,
+		Role:         role,
+		AccountId:    access.AccountId,
+		AccessKeyId:  access.AccessKeyId,
+		SecretKey:    access.SecretKey,
+		SessionToken: access.SessionToken,
+		SessionName:  access.SessionName,
+		Variant:      variant,
+		Expiration:   access.Expiration,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	if err = os.WriteFile(c.filePath(profile, account, role, variant), data, 0600); err != nil {
+		return fmt.Errorf("failed to store credentials in cache: %w", err)
+	}
+	return nil
+}
+
+// List returns all cached credentials including expired ones
+func (c *CredentialCache) List() ([]CachedCredentials, error) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credential cache: %w", err)
+	}
+
+	var result []CachedCredentials
+	for _, path := range paths {
+		cached, err := c.read(path)
+		if err != nil {
+			log.WithError(err).Warnf("skipping invalid cache file %s", path)
+			continue
+		}
+		result = append(result, cached)
+	}
+	return result, nil
+}
+
+// Clear deletes all cached credentials matching the filter and returns the number of deleted entries
+func (c *CredentialCache) Clear(filter func(cached CachedCredentials) bool) (int, error) {
+	entries, err := c.List()
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, entry := range entries {
+		if !filter(entry) {
+			continue
+		}
+		if err = os.Remove(c.filePath(entry.Profile, entry.Account, entry.Role, entry.Variant)); err != nil {
+			return deleted, fmt.Errorf("failed to delete cached credentials: %w", err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (c *CredentialCache) read(path string) (CachedCredentials, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return CachedCredentials{}, err
+	}
+
+	cached := CachedCredentials{}
+	if err = yaml.Unmarshal(content, &cached); err != nil {
+		return CachedCredentials{}, fmt.Errorf("could not parse cache file %q: %w", path, err)
+	}
+	return cached, nil
+}
+
+func (c *CredentialCache) filePath(profile, account, role, variant string) string {
+	if role == "" {
+		role = "default"
+	}
+	parts := []string{profile, strings.ToLower(account), role}
+	if variant != "" {
+		parts = append(parts, variant)
+	}
+	name := strings.Join(parts, "_")
+	return filepath.Join(c.dir, cacheFileNameInvalidChars.ReplaceAllString(name, "-")+".yaml")
+}
+
+// cachingAws reuses credentials of previous invocations until shortly before they expire
+type cachingAws struct {
+	IAws
+	profile string
+	cache   *CredentialCache
+}
+
+func newCachingAws(strategy IAws, profile string) IAws {
+	return &cachingAws{
+		IAws:    strategy,
+		profile: profile,
+		cache:   NewCredentialCache(),
+	}
+}
+
+func (c *cachingAws) AssumeRole(role, accountName string) (*AwsAccountAccess, error) {
+	return c.cached(accountName, role, cacheVariant(false, c.Session()), func() (*AwsAccountAccess, error) {
+		return c.IAws.AssumeRole(role, accountName)
+	})
+}
+
+func (c *cachingAws) AssumeRoleWithMfa(role, accountName string) (*AwsAccountAccess, error) {
+	return c.cached(accountName, role, cacheVariant(true, c.Session()), func() (*AwsAccountAccess, error) {
+		return c.IAws.AssumeRoleWithMfa(role, accountName)
+	})
+}
+
+func (c *cachingAws) SessionToken(duration time.Duration) (*AwsAccountAccess, error) {
+	// session tokens have no session name or tags
+	variant := cacheVariant(c.SessionTokenRequiresMfa(), SessionOptions{Duration: duration})
+	return c.cached("", sessionTokenCacheKey, variant, func() (*AwsAccountAccess, error) {
+		return c.IAws.SessionToken(duration)
+	})
+}
+
+func (c *cachingAws) Logout() error {
+	if err := c.IAws.Logout(); err != nil {
+		return err
+	}
+
+	deleted, err := c.cache.Clear(func(cached CachedCredentials) bool {
+		return cached.Profile == c.profile
+	})
+	if err != nil {
+		return err
+	}
+	log.Debugf("deleted %d cached credentials", deleted)
+	return nil
+}
+
+// cached returns the cached credentials of the variant or creates and caches new ones. Credentials of another session
+// name (e.g. of another deployment) would show up wrongly in the audit logs and credentials without MFA may lack
+// permissions, so each variant has its own entry.
+func (c *cachingAws) cached(account, role, variant string, create func() (*AwsAccountAccess, error)) (*AwsAccountAccess, error) {
+	if access, found := c.cache.Get(c.profile, account, role, variant); found {
+		return access, nil
+	}
+
+	access, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.cache.Put(c.profile, account, role, variant, access); err != nil {
+		log.WithError(err).Warn("failed to cache credentials")
+	}
+	return access, nil
+}
+
+// cacheVariant is a short hash of the MFA flag and the effective session options
+func cacheVariant(mfa bool, session SessionOptions) string {
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%t\x00%s\x00%s\x00%s\x00", mfa, session.Duration, session.RoleSessionName(), session.sourceIdentity())
+	keys := lo.Keys(session.Tags)
+	slices.Sort(keys)
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(hash, "%s=%s\x00", key, session.Tags[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
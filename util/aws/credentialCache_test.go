@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCredentialCache(t *testing.T) {
+	cache := &CredentialCache{dir: t.TempDir()}
+
+	valid := &AwsAccountAccess{AccountId: "1", AccessKeyId: "key", Expiration: time.Now().Add(time.Hour)}
+	expiring := &AwsAccountAccess{AccountId: "2", AccessKeyId: "key", Expiration: time.Now().Add(time.Minute)}
+
+	if err := cache.Put("work", "Dev", "Admin", "", valid); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put("work", "prod", "", "", expiring); err != nil {
+		t.Fatal(err)
+	}
+
+	access, found := cache.Get("work", "dev", "Admin", "")
+	if !found || access.AccountId != "1" {
+		t.Errorf("Get() = %v, %v, want cached credentials of account 1", access, found)
+	}
+
+	if _, found = cache.Get("work", "prod", "", ""); found {
+		t.Errorf("Get() returned credentials expiring within the renewal margin")
+	}
+
+	if _, found = cache.Get("other", "dev", "Admin", ""); found {
+		t.Errorf("Get() returned credentials of another profile")
+	}
+
+	if _, found = cache.Get("work", "dev", "Admin", cacheVariant(true, SessionOptions{})); found {
+		t.Errorf("Get() returned credentials of another variant")
+	}
+
+	info, err := os.Stat(filepath.Join(cache.dir, "work_dev_Admin.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("cache file permissions = %v, want 0600", info.Mode().Perm())
+	}
+
+	deleted, err := cache.Clear(func(cached CachedCredentials) bool {
+		return cached.Account == "prod"
+	})
+	if err != nil || deleted != 1 {
+		t.Errorf("Clear() = %d, %v, want 1 deleted entry", deleted, err)
+	}
+
+	entries, err := cache.List()
+	if err != nil || len(entries) != 1 || entries[0].Account != "Dev" {
+		t.Errorf("List() = %v, %v, want only the entry of Dev", entries, err)
+	}
+}
+
+func TestCacheVariant(t *testing.T) {
+	session := SessionOptions{NameTemplate: "deploy", Tags: map[string]string{"team": "a", "cost": "b"}}
+	variant := cacheVariant(false, session)
+
+	if cacheVariant(true, session) == variant {
+		t.Errorf("cacheVariant() ignores the MFA flag")
+	}
+	if other := (SessionOptions{NameTemplate: "other", Tags: session.Tags}); cacheVariant(false, other) == variant {
+		t.Errorf("cacheVariant() ignores the session name")
+	}
+	if other := (SessionOptions{NameTemplate: "deploy", Tags: map[string]string{"team": "b"}}); cacheVariant(false, other) == variant {
+		t.Errorf("cacheVariant() ignores the tags")
+	}
+	if other := (SessionOptions{NameTemplate: "deploy", Duration: time.Hour, Tags: session.Tags}); cacheVariant(false, other) == variant {
+		t.Errorf("cacheVariant() ignores the duration")
+	}
+	if cacheVariant(false, session) != variant {
+		t.Errorf("cacheVariant() is not stable")
+	}
+}
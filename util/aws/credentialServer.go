@@ -0,0 +1,150 @@
+package aws
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// CredentialServer serves credentials like the ECS container credentials endpoint. AWS SDKs and Terraform use it via
+// AWS_CONTAINER_CREDENTIALS_FULL_URI and request new credentials shortly before the current ones expire.
+type CredentialServer struct {
+	server   *http.Server
+	listener net.Listener
+	token    string
+	provide  func() (*AwsAccountAccess, error)
+
+	mutex   sync.Mutex
+	current *AwsAccountAccess
+}
+
+type containerCredentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	Token           string
+	Expiration      string `json:",omitempty"`
+	AccountId       string `json:",omitempty"`
+}
+
+// ListenCredentialServer starts serving the credentials on the address. The provide function is called for new credentials
+// when the current ones expire within the renewal margin.
+func ListenCredentialServer(address string, current *AwsAccountAccess, provide func() (*AwsAccountAccess, error)) (*CredentialServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err = rand.Read(tokenBytes); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to create authorization token: %w", err)
+	}
+
+	s := &CredentialServer{
+		listener: listener,
+		token:    hex.EncodeToString(tokenBytes),
+		provide:  provide,
+		current:  current,
+	}
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Error("credential server stopped")
+		}
+	}()
+	return s, nil
+}
+
+func (s *CredentialServer) Url() string {
+	return fmt.Sprintf("http://%s/credentials", s.listener.Addr().String())
+}
+
+func (s *CredentialServer) Token() string {
+	return s.token
+}
+
+// ShadowingEnvs are the environment variables of credentials, which AWS SDKs prefer over the server
+var ShadowingEnvs = []string{
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"AWS_PROFILE",
+	"AWS_DEFAULT_PROFILE",
+	"AWS_WEB_IDENTITY_TOKEN_FILE",
+	"AWS_ROLE_ARN",
+	"AWS_ROLE_SESSION_NAME",
+	"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+}
+
+// Env returns the environment variables pointing AWS SDKs to the server. The shared config and credentials files are
+// replaced by empty ones, as the SDKs prefer the credentials of their default profile.
+func (s *CredentialServer) Env() map[string]string {
+	return map[string]string{
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI": s.Url(),
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN":  s.token,
+		"AWS_CONFIG_FILE":                    os.DevNull,
+		"AWS_SHARED_CREDENTIALS_FILE":        os.DevNull,
+	}
+}
+
+func (s *CredentialServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+func (s *CredentialServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(s.token)) != 1 {
+		http.Error(w, "invalid authorization token", http.StatusUnauthorized)
+		return
+	}
+
+	access, err := s.credentials()
+	if err != nil {
+		log.WithError(err).Error("failed to refresh credentials")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := containerCredentials{
+		AccessKeyId:     access.AccessKeyId,
+		SecretAccessKey: access.SecretKey,
+		Token:           access.SessionToken,
+		AccountId:       access.AccountId,
+	}
+	if !access.Expiration.IsZero() {
+		response.Expiration = access.Expiration.UTC().Format(time.RFC3339)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (s *CredentialServer) credentials() (*AwsAccountAccess, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.current != nil && (s.current.Expiration.IsZero() || s.current.Expiration.After(time.Now().Add(credentialRenewalMargin))) {
+		return s.current, nil
+	}
+
+	log.Info("refreshing credentials")
+	access, err := s.provide()
+	if err != nil {
+		return nil, err
+	}
+	s.current = access
+	return access, nil
+}
@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCredentialServer(t *testing.T) {
+	expiring := &AwsAccountAccess{AccessKeyId: "old", Expiration: time.Now().Add(time.Minute)}
+	refreshed := &AwsAccountAccess{AccessKeyId: "new", Expiration: time.Now().Add(time.Hour)}
+
+	calls := 0
+	server, err := ListenCredentialServer("127.0.0.1:0", expiring, func() (*AwsAccountAccess, error) {
+		calls++
+		return refreshed, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = server.Close()
+	}()
+
+	request := func(token string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, server.Url(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", token)
+		return http.DefaultClient.Do(req)
+	}
+
+	response, err := request("wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d without valid token", response.StatusCode, http.StatusUnauthorized)
+	}
+
+	for range 2 {
+		response, err = request(server.Token())
+		if err != nil {
+			t.Fatal(err)
+		}
+		credentials := containerCredentials{}
+		if err = json.NewDecoder(response.Body).Decode(&credentials); err != nil {
+			t.Fatal(err)
+		}
+		_ = response.Body.Close()
+		if credentials.AccessKeyId != "new" {
+			t.Errorf("AccessKeyId = %s, want refreshed credentials", credentials.AccessKeyId)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("credentials were refreshed %d times, want once", calls)
+	}
+}
@@ -1,14 +1,12 @@
 package aws
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
-	"github.com/IronFE/iron.cli/util"
+	ironConfig "github.com/IronFE/iron.cli/util/config"
 	"github.com/apex/log"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -24,35 +22,101 @@ type AwsOrganizationInfo struct {
 type awsAbstraction struct {
 	config aws.Config
 	region string
-	mfaSerial string
+	mfa    *mfaCodes
+	// profile is the name of the iron profile, which the account list is cached for
+	profile      string
+	accountCache *AccountCache
+	session      SessionOptions
 }
 
-func newCredsStrategyAws(profileName string, region string, mfaSerial string) (IAws, error) {
+func newCredsStrategyForProfile(profile ironConfig.Profile, session SessionOptions) (IAws, error) {
+	if profile.IAM == nil {
+		return nil, errors.Errorf("the profile has no `iam` section")
+	}
+	mfa := &mfaCodes{serial: profile.IAM.MfaSerial, provider: profile.IAM.MfaProvider}
+	return newCredsStrategyAws(profile.Name, profile.IAM.ProfileName, profile.DefaultRegion, mfa, session)
+}
+
+func newCredsStrategyAws(profile string, profileName string, region string, mfa *mfaCodes, session SessionOptions) (*awsAbstraction, error) {
 	defaultConfig, err := config.LoadDefaultConfig(context.Background(), config.WithSharedConfigProfile(profileName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	return newAwsAbstraction(defaultConfig, profile, region, mfa, session), nil
+}
+
+func newAwsAbstraction(cfg aws.Config, profile string, region string, mfa *mfaCodes, session SessionOptions) *awsAbstraction {
 	return &awsAbstraction{
-		config: defaultConfig,
-		region: region,
-		mfaSerial: mfaSerial,
-	}, nil
+		config:       cfg,
+		region:       region,
+		mfa:          mfa,
+		profile:      profile,
+		accountCache: NewAccountCache(),
+		session:      session,
+	}
 }
 
+// FindAccountId resolves an account by its id, its name or its OU path (e.g. `workloads/prod/payments`)
 func (a *awsAbstraction) FindAccountId(alias string) (string, error) {
-	client := organizations.NewFromConfig(a.config)
-	response, err := client.ListAccounts(context.Background(), &organizations.ListAccountsInput{})
+	if isAccountId(alias) {
+		return alias, nil
+	}
+
+	if strings.Contains(alias, "/") {
+		return a.findAccountIdByPath(alias)
+	}
+
+	accounts, err := a.cachedAccounts()
 	if err != nil {
-		return "", errors.Wrap(err, "failed to list accounts")
+		return "", err
 	}
 
-	for _, account := range response.Accounts {
-		if strings.ToLower(*account.Name) == strings.ToLower(alias) {
-			return *account.Id, nil
-		}
+	account, err := matchAccount(accounts, alias)
+	if err != nil {
+		return "", err
 	}
+	return account.Id, nil
+}
 
-	return "", errors.Errorf("Could not find account with alias %s", alias)
+// cachedAccounts returns the accounts of the organization, which are cached for some time as listing them takes a while
+func (a *awsAbstraction) cachedAccounts() ([]AccountInfo, error) {
+	accounts, updatedAt, err := a.accountCache.Get(a.profile)
+	if err != nil {
+		log.WithError(err).Warn("failed to read cached accounts")
+	}
+	if err == nil && accounts != nil && time.Since(updatedAt) < accountCacheTTL {
+		return accounts, nil
+	}
+
+	if accounts, err = a.ListAccounts(); err != nil {
+		return nil, err
+	}
+	if err = a.accountCache.Put(a.profile, accounts); err != nil {
+		log.WithError(err).Warn("failed to cache accounts")
+	}
+	return accounts, nil
+}
+
+func (a *awsAbstraction) ListAccounts() ([]AccountInfo, error) {
+	client := organizations.NewFromConfig(a.config)
+	paginator := organizations.NewListAccountsPaginator(client, &organizations.ListAccountsInput{})
+
+	var accounts []AccountInfo
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list accounts")
+		}
+
+		for _, account := range page.Accounts {
+			accounts = append(accounts, AccountInfo{
+				Id:    aws.ToString(account.Id),
+				Name:  aws.ToString(account.Name),
+				Email: aws.ToString(account.Email),
+			})
+		}
+	}
+	return accounts, nil
 }
 
 func (a *awsAbstraction) OrganizationInfo() (AwsOrganizationInfo, error) {
@@ -81,20 +145,16 @@ func (a *awsAbstraction) AssumeRoleWithMfa(role, accountName string) (*AwsAccoun
 func (a *awsAbstraction) SessionToken(duration time.Duration) (*AwsAccountAccess, error) {
 	client := sts.NewFromConfig(a.config)
 
-	fmt.Printf("Enter MFA: ")
-	reader := bufio.NewReader(os.Stdin)
-	mfa, err := reader.ReadString('\n')
-	if err != nil {
-		return nil, fmt.Errorf("failed to read MFA")
-	}
-
-	input := &sts.GetSessionTokenInput{
-		DurationSeconds: aws.Int32(int32(duration.Seconds())),
-		SerialNumber:    aws.String(a.mfaSerial),
-		TokenCode:       aws.String(strings.Trim(mfa, "\n")),
-	}
-
-	output, err := client.GetSessionToken(context.Background(), input)
+	var output *sts.GetSessionTokenOutput
+	err := a.mfa.withMfa(func(serial, code string) error {
+		var err error
+		output, err = client.GetSessionToken(context.Background(), &sts.GetSessionTokenInput{
+			DurationSeconds: aws.Int32(int32(duration.Seconds())),
+			SerialNumber:    aws.String(serial),
+			TokenCode:       aws.String(code),
+		})
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session token: %w", err)
 	}
@@ -103,49 +163,61 @@ func (a *awsAbstraction) SessionToken(duration time.Duration) (*AwsAccountAccess
 		AccessKeyId:  *output.Credentials.AccessKeyId,
 		SecretKey:    *output.Credentials.SecretAccessKey,
 		SessionToken: *output.Credentials.SessionToken,
+		Expiration:   aws.ToTime(output.Credentials.Expiration),
 	}, nil
 }
 
+func (a *awsAbstraction) SessionTokenRequiresMfa() bool {
+	return true
+}
+
+func (a *awsAbstraction) Login(options LoginOptions) error {
+	return fmt.Errorf("a login is not supported by the iam strategy")
+}
+
+func (a *awsAbstraction) Logout() error {
+	return nil
+}
+
+func (a *awsAbstraction) LoginExpiration() (time.Time, error) {
+	return time.Time{}, nil
+}
+
 func (a *awsAbstraction) Region() string {
 	return a.region
 }
 
+func (a *awsAbstraction) Session() SessionOptions {
+	return a.session
+}
+
 func (a *awsAbstraction) assumeRole(role, accountName string, mfa bool) (*AwsAccountAccess, error) {
 	accountId, err := a.FindAccountId(accountName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account id for account %q: %w", accountName, err)
 	}
 
-	hostname, err := os.Hostname()
-	if err != nil {
-		hostname = "Unknown"
-	}
-
 	client := sts.NewFromConfig(a.config)
 	input := &sts.AssumeRoleInput{
-		RoleArn:         aws.String(fmt.Sprintf("arn:aws:iam::%s:role/%s", accountId, role)),
-		RoleSessionName: aws.String(fmt.Sprintf("IronCLI@%s", hostname)),
+		RoleArn: aws.String(roleArn(accountId, role)),
 	}
+	a.session.apply(input)
 
+	var response *sts.AssumeRoleOutput
 	if mfa {
-		mfaSerial, err := util.AskUser("Enter MFA Serial")
-		if err != nil {
-			return nil, fmt.Errorf("user did not enter mfa serial: %w", err)
-		}
-
-		token, err := util.AskUser("Enter Token")
-		if err != nil {
-			return nil, fmt.Errorf("user did not enter mfa token: %w", err)
-		}
-
-		input.SerialNumber = aws.String(mfaSerial)
-		input.TokenCode = aws.String(token)
-		log.Infof("Assuming role %s with mfa %s", *input.RoleArn, mfaSerial)
+		err = a.mfa.withMfa(func(serial, code string) error {
+			input.SerialNumber = aws.String(serial)
+			input.TokenCode = aws.String(code)
+			log.Infof("Assuming role %s with mfa %s", *input.RoleArn, serial)
+
+			var err error
+			response, err = client.AssumeRole(context.Background(), input)
+			return err
+		})
 	} else {
 		log.Infof("Assuming role %s", *input.RoleArn)
+		response, err = client.AssumeRole(context.Background(), input)
 	}
-
-	response, err := client.AssumeRole(context.Background(), input)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to assume role %s in account %s", role, accountId)
 	}
@@ -155,5 +227,11 @@ func (a *awsAbstraction) assumeRole(role, accountName string, mfa bool) (*AwsAcc
 		AccessKeyId:  *response.Credentials.AccessKeyId,
 		SecretKey:    *response.Credentials.SecretAccessKey,
 		SessionToken: *response.Credentials.SessionToken,
+		Expiration:   aws.ToTime(response.Credentials.Expiration),
+		SessionName:  *input.RoleSessionName,
 	}, nil
 }
+
+func roleArn(accountId, role string) string {
+	return fmt.Sprintf("arn:aws:iam::%s:role/%s", accountId, role)
+}
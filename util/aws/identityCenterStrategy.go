@@ -4,35 +4,60 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	ironConfig "github.com/IronFE/iron.cli/util/config"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sso"
 	"github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/pkg/errors"
 )
 
 type identityCenterStrategy struct {
+	authMutex            sync.Mutex
 	authToken            string
 	ssoProvider          *fileCachedAuthProvider
 	startUrl             string
 	defaultRole          string
 	defaultRegion        string
 	identityCenterRegion string
+	// session is only used for roles chained after the Identity Center role
+	session SessionOptions
 }
 
-func NewIdentityCenterStrategy(startUrl, defaultRole, defaultRegion, identityCenterRegion string) IAws {
+func newIdentityCenterStrategyForProfile(profile ironConfig.Profile, session SessionOptions) (IAws, error) {
+	if profile.IdentityCenter == nil {
+		return nil, errors.Errorf("the profile has no `identityCenter` section")
+	}
+
+	idcRegion := profile.IdentityCenter.Region
+	if idcRegion == "" {
+		idcRegion = profile.DefaultRegion
+	}
+
+	return NewIdentityCenterStrategy(
+		profile.IdentityCenter.StartUrl,
+		profile.IdentityCenter.DefaultRole,
+		profile.DefaultRegion,
+		idcRegion,
+		profile.IdentityCenter.NoBrowser,
+		session)
+}
+
+func NewIdentityCenterStrategy(startUrl, defaultRole, defaultRegion, identityCenterRegion string, noBrowser bool, session SessionOptions) (IAws, error) {
 	if startUrl == "" {
-		panic("parameter `startUrl` must not be empty")
+		return nil, errors.Errorf("`startUrl` must not be empty")
 	}
 	if defaultRole == "" {
-		panic("parameter `defaultRole` must not be empty")
+		return nil, errors.Errorf("`defaultRole` must not be empty")
 	}
 	if defaultRegion == "" {
-		panic("default region cannot be empty")
+		return nil, errors.Errorf("`defaultRegion` must not be empty")
 	}
 	if identityCenterRegion == "" {
-		panic("parameter `identityCenterRegion` must not be empty")
+		return nil, errors.Errorf("the identity center `region` must not be empty")
 	}
 
 	return &identityCenterStrategy{
@@ -40,10 +65,12 @@ func NewIdentityCenterStrategy(startUrl, defaultRole, defaultRegion, identityCen
 		ssoProvider: &fileCachedAuthProvider{
 			startUrl: startUrl,
 			region:   identityCenterRegion,
+			options:  LoginOptions{NoBrowser: noBrowser},
 		},
 		defaultRegion:        defaultRegion,
 		identityCenterRegion: identityCenterRegion,
-	}
+		session:              session,
+	}, nil
 }
 
 func (s *identityCenterStrategy) AssumeRole(role, accountName string) (*AwsAccountAccess, error) {
@@ -62,26 +89,31 @@ func (s *identityCenterStrategy) AssumeRole(role, accountName string) (*AwsAccou
 	}
 
 	ssoClient := sso.NewFromConfig(cfg)
-	accountInfo, err := s.findAccount(accountName, cfg)
-	if err != nil {
-		return nil, err
+	accountId := accountName
+	if !isAccountId(accountName) {
+		accountInfo, err := s.findAccount(accountName, cfg)
+		if err != nil {
+			return nil, err
+		}
+		accountId = *accountInfo.AccountId
 	}
 
 	credsInput := sso.GetRoleCredentialsInput{
 		AccessToken: aws.String(token),
-		AccountId:   accountInfo.AccountId,
+		AccountId:   aws.String(accountId),
 		RoleName:    aws.String(role),
 	}
 
 	credsOutput, err := ssoClient.GetRoleCredentials(context.Background(), &credsInput)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get role credentials for account %s and role %s: %w", *accountInfo.AccountId, role, err)
+		return nil, fmt.Errorf("failed to get role credentials for account %s and role %s: %w", accountId, role, err)
 	}
 	return &AwsAccountAccess{
-		AccountId:    *accountInfo.AccountId,
+		AccountId:    accountId,
 		AccessKeyId:  *credsOutput.RoleCredentials.AccessKeyId,
 		SecretKey:    *credsOutput.RoleCredentials.SecretAccessKey,
 		SessionToken: *credsOutput.RoleCredentials.SessionToken,
+		Expiration:   time.UnixMilli(credsOutput.RoleCredentials.Expiration),
 	}, nil
 
 }
@@ -91,7 +123,80 @@ func (s *identityCenterStrategy) AssumeRoleWithMfa(role, accountName string) (*A
 }
 
 func (s *identityCenterStrategy) FindAccountId(alias string) (string, error) {
-	return "", fmt.Errorf("not supported")
+	if isAccountId(alias) {
+		return alias, nil
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithDefaultRegion(s.identityCenterRegion))
+	if err != nil {
+		return "", fmt.Errorf("failed to create default config: %w", err)
+	}
+
+	account, err := s.findAccount(alias, cfg)
+	if err != nil {
+		return "", err
+	}
+	return *account.AccountId, nil
+}
+
+func (s *identityCenterStrategy) ListAccounts() ([]AccountInfo, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithDefaultRegion(s.identityCenterRegion))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default config: %w", err)
+	}
+
+	token, err := s.ssoAuth()
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate to AWS: %w", err)
+	}
+
+	ssoClient := sso.NewFromConfig(cfg)
+	accountPaginator := sso.NewListAccountsPaginator(ssoClient, &sso.ListAccountsInput{
+		AccessToken: aws.String(token),
+	})
+
+	var accounts []AccountInfo
+	for accountPaginator.HasMorePages() {
+		page, err := accountPaginator.NextPage(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account page: %w", err)
+		}
+
+		for _, account := range page.AccountList {
+			roles, err := s.listAccountRoles(ssoClient, token, aws.ToString(account.AccountId))
+			if err != nil {
+				return nil, err
+			}
+
+			accounts = append(accounts, AccountInfo{
+				Id:    aws.ToString(account.AccountId),
+				Name:  aws.ToString(account.AccountName),
+				Email: aws.ToString(account.EmailAddress),
+				Roles: roles,
+			})
+		}
+	}
+	return accounts, nil
+}
+
+func (s *identityCenterStrategy) listAccountRoles(ssoClient *sso.Client, token, accountId string) ([]string, error) {
+	rolePaginator := sso.NewListAccountRolesPaginator(ssoClient, &sso.ListAccountRolesInput{
+		AccessToken: aws.String(token),
+		AccountId:   aws.String(accountId),
+	})
+
+	var roles []string
+	for rolePaginator.HasMorePages() {
+		page, err := rolePaginator.NextPage(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list roles of account %s: %w", accountId, err)
+		}
+
+		for _, role := range page.RoleList {
+			roles = append(roles, aws.ToString(role.RoleName))
+		}
+	}
+	return roles, nil
 }
 
 func (s *identityCenterStrategy) OrganizationInfo() (AwsOrganizationInfo, error) {
@@ -102,13 +207,52 @@ func (s *identityCenterStrategy) Region() string {
 	return s.defaultRegion
 }
 
+func (s *identityCenterStrategy) Session() SessionOptions {
+	return s.session
+}
+
 func (s *identityCenterStrategy) SessionToken(duration time.Duration) (*AwsAccountAccess, error) {
 	return nil, fmt.Errorf("a direct session token is not supported by the identity center strategy")
 }
 
+func (s *identityCenterStrategy) SessionTokenRequiresMfa() bool {
+	return false
+}
+
+func (s *identityCenterStrategy) Login(options LoginOptions) error {
+	s.authMutex.Lock()
+	defer s.authMutex.Unlock()
+
+	authToken, err := s.ssoProvider.Login(options)
+	if err != nil {
+		return fmt.Errorf("failed to log in: %w", err)
+	}
+
+	s.authToken = authToken
+	return nil
+}
+
+func (s *identityCenterStrategy) Logout() error {
+	s.authMutex.Lock()
+	defer s.authMutex.Unlock()
+
+	s.authToken = ""
+	return s.ssoProvider.Logout()
+}
+
+func (s *identityCenterStrategy) LoginExpiration() (time.Time, error) {
+	return s.ssoProvider.Expiration()
+}
+
 func (s *identityCenterStrategy) ssoAuth() (string, error) {
+	s.authMutex.Lock()
+	defer s.authMutex.Unlock()
+
+	// long-running operations outlive the token, so the expiration is checked on every use
 	if s.authToken != "" {
-		return s.authToken, nil
+		if expiration, err := s.ssoProvider.Expiration(); err == nil && time.Now().Before(expiration) {
+			return s.authToken, nil
+		}
 	}
 
 	authToken, err := s.ssoProvider.Auth()
@@ -131,6 +275,7 @@ func (s *identityCenterStrategy) findAccount(name string, cfg aws.Config) (types
 		AccessToken: aws.String(token),
 	})
 
+	var names []string
 	for accountPaginator.HasMorePages() {
 		page, err := accountPaginator.NextPage(context.Background())
 		if err != nil {
@@ -138,10 +283,11 @@ func (s *identityCenterStrategy) findAccount(name string, cfg aws.Config) (types
 		}
 
 		for _, account := range page.AccountList {
-			if strings.ToLower(*account.AccountName) == strings.ToLower(name) {
+			if strings.EqualFold(*account.AccountName, name) || *account.AccountId == name {
 				return account, nil
 			}
+			names = append(names, *account.AccountName)
 		}
 	}
-	return types.AccountInfo{}, fmt.Errorf("no aws account %q found", name)
+	return types.AccountInfo{}, accountNotFound(name, names)
 }
@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/IronFE/iron.cli/util/config"
+	"gopkg.in/yaml.v3"
+)
+
+type cachedInstances struct {
+	UpdatedAt time.Time `yaml:"updatedAt"`
+	Names     []string  `yaml:"names"`
+}
+
+// InstanceCache stores the names of the EC2 instances found in an account, e.g. for shell completion
+type InstanceCache struct {
+	dir string
+}
+
+func NewInstanceCache() *InstanceCache {
+	return &InstanceCache{dir: filepath.Join(config.BaseDir(), "instances")}
+}
+
+// Get returns the cached instance names of the account and the time they were cached
+func (c *InstanceCache) Get(profile, account string) ([]string, time.Time, error) {
+	content, err := os.ReadFile(c.filePath(profile, account))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, time.Time{}, nil
+		}
+		return nil, time.Time{}, fmt.Errorf("failed to read instance cache: %w", err)
+	}
+
+	cached := cachedInstances{}
+	if err = yaml.Unmarshal(content, &cached); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to parse instance cache: %w", err)
+	}
+	return cached.Names, cached.UpdatedAt, nil
+}
+
+func (c *InstanceCache) Put(profile, account string, names []string) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create instance cache folder: %w", err)
+	}
+
+	data, err := yaml.Marshal(cachedInstances{UpdatedAt: time.Now(), Names: names})
+	if err != nil {
+		return fmt.Errorf("failed to marshal instance names: %w", err)
+	}
+
+	if err = os.WriteFile(c.filePath(profile, account), data, 0600); err != nil {
+		return fmt.Errorf("failed to write instance cache: %w", err)
+	}
+	return nil
+}
+
+func (c *InstanceCache) filePath(profile, account string) string {
+	name := profile + "_" + strings.ToLower(account)
+	return filepath.Join(c.dir, cacheFileNameInvalidChars.ReplaceAllString(name, "-")+".yaml")
+}
@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/IronFE/iron.cli/util"
+	ironConfig "github.com/IronFE/iron.cli/util/config"
+	"github.com/apex/log"
+	"github.com/aws/smithy-go"
+	"github.com/pkg/errors"
+)
+
+const (
+	mfaAttempts   = 3
+	totpPeriod    = 30 * time.Second
+	totpCodeDigit = 6
+)
+
+var mfaCodePattern = regexp.MustCompile(`^\d{6}$`)
+
+var errInvalidMfaCode = errors.Errorf("an MFA code consists of %d digits", totpCodeDigit)
+
+// mfaCodes provides the serial and the codes for calls requiring MFA
+type mfaCodes struct {
+	serial   string
+	provider *ironConfig.MfaProvider
+	// the last code of a TOTP seed, which AWS does not accept twice
+	lastCode string
+}
+
+// withMfa calls the function with an MFA serial and code. Codes which are not 6 digits or which AWS rejects are retried
+// if the source provides a new code, i.e. the prompt and TOTP seeds. Failures of the code provider and invalid codes of a
+// command are returned right away.
+func (m *mfaCodes) withMfa(call func(serial, code string) error) error {
+	serial := m.serial
+	if serial == "" {
+		var err error
+		if serial, err = util.AskUser("Enter MFA Serial"); err != nil {
+			return fmt.Errorf("user did not enter mfa serial: %w", err)
+		}
+	}
+
+	var err error
+	for attempt := 1; attempt <= mfaAttempts; attempt++ {
+		var code string
+		if code, err = m.code(); errors.Is(err, errInvalidMfaCode) && m.retryable() {
+			log.Warnf("%s (attempt %d of %d)", err, attempt, mfaAttempts)
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to get an MFA code: %w", err)
+		}
+
+		if err = call(serial, code); err == nil || !isMfaRejected(err) {
+			return err
+		}
+		if !m.retryable() {
+			return fmt.Errorf("the MFA code of the command was rejected: %w", err)
+		}
+		log.Warnf("the MFA code was rejected (attempt %d of %d)", attempt, mfaAttempts)
+	}
+	return fmt.Errorf("no valid MFA code after %d attempts: %w", mfaAttempts, err)
+}
+
+// retryable reports whether another attempt gets a new code. A command would most likely return the same code again.
+func (m *mfaCodes) retryable() bool {
+	return m.provider == nil || m.provider.Command == ""
+}
+
+func (m *mfaCodes) code() (string, error) {
+	var code string
+	var err error
+	switch {
+	case m.provider != nil && m.provider.Command != "":
+		code, err = commandCode(m.provider.Command)
+	case m.provider != nil && m.provider.Keyring != nil:
+		code, err = m.keyringCode(*m.provider.Keyring)
+	default:
+		code, err = util.AskUser("Enter MFA Token")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	code = strings.TrimSpace(code)
+	if !mfaCodePattern.MatchString(code) {
+		return "", errInvalidMfaCode
+	}
+	return code, nil
+}
+
+func commandCode(command string) (string, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	output, err := exec.Command(shell, flag, command).Output()
+	if err != nil {
+		return "", fmt.Errorf("the MFA command failed: %w", err)
+	}
+	return string(output), nil
+}
+
+// keyringCode generates a TOTP code from the seed in the keyring. A code is never returned twice, instead the next period is awaited.
+func (m *mfaCodes) keyringCode(entry ironConfig.KeyringEntry) (string, error) {
+	seed, err := readKeyring(entry)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := totp(seed, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if code == m.lastCode {
+		wait := totpPeriod - time.Duration(time.Now().Unix()%int64(totpPeriod.Seconds()))*time.Second
+		log.Infof("waiting %s for the next MFA code", wait)
+		time.Sleep(wait)
+		if code, err = totp(seed, time.Now()); err != nil {
+			return "", err
+		}
+	}
+	m.lastCode = code
+	return code, nil
+}
+
+// readKeyring reads a secret with the tools of the OS
+func readKeyring(entry ironConfig.KeyringEntry) (string, error) {
+	if entry.Service == "" || entry.Account == "" {
+		return "", errors.Errorf("the keyring entry requires a service and an account")
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", entry.Service, "-a", entry.Account, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", entry.Service, "account", entry.Account)
+	default:
+		return "", errors.Errorf("the keyring is not supported on %s; use an MFA command instead", runtime.GOOS)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the TOTP seed from the keyring: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// totp generates a code according to RFC 6238 with the default parameters used by AWS (SHA1, 6 digits, 30 seconds)
+func totp(seed string, at time.Time) (string, error) {
+	seed = strings.ToUpper(strings.ReplaceAll(seed, " ", ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(seed, "="))
+	if err != nil {
+		return "", fmt.Errorf("the TOTP seed is not base32 encoded: %w", err)
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(at.Unix()/int64(totpPeriod.Seconds())))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000), nil
+}
+
+// isMfaRejected reports whether AWS rejected the MFA code
+func isMfaRejected(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.ErrorMessage(), "MultiFactorAuthentication")
+}
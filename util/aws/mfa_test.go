@@ -0,0 +1,78 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	ironConfig "github.com/IronFE/iron.cli/util/config"
+	"github.com/aws/smithy-go"
+)
+
+func TestTotp(t *testing.T) {
+	// test vectors of RFC 6238 truncated to 6 digits
+	const seed = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	tests := []struct {
+		name string
+		at   int64
+		want string
+	}{
+		{name: "first period", at: 59, want: "287082"},
+		{name: "leading zero", at: 1111111109, want: "081804"},
+		{name: "later", at: 1234567890, want: "005924"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := totp(seed, time.Unix(tt.at, 0))
+			if err != nil {
+				t.Fatalf("totp() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("totp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotpInvalidSeed(t *testing.T) {
+	if _, err := totp("not base32!", time.Now()); err == nil {
+		t.Error("totp() expected an error for an invalid seed")
+	}
+}
+
+func TestWithMfa(t *testing.T) {
+	rejected := &smithy.GenericAPIError{Code: "AccessDenied", Message: "MultiFactorAuthentication failed with invalid MFA one time pass code."}
+	tests := []struct {
+		name      string
+		command   string
+		callErr   error
+		wantCalls int
+		wantErr   string
+	}{
+		{name: "valid", command: "echo 123456", wantCalls: 1},
+		{name: "rejected", command: "echo 123456", callErr: rejected, wantCalls: 1, wantErr: "the MFA code of the command was rejected"},
+		{name: "other error", command: "echo 123456", callErr: &smithy.GenericAPIError{Code: "AccessDenied"}, wantCalls: 1, wantErr: "AccessDenied"},
+		{name: "invalid code", command: "echo 1234", wantCalls: 0, wantErr: "an MFA code consists of 6 digits"},
+		{name: "failing command", command: "exit 1", wantCalls: 0, wantErr: "the MFA command failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mfa := &mfaCodes{serial: "arn:aws:iam::123456789012:mfa/jane", provider: &ironConfig.MfaProvider{Command: tt.command}}
+
+			calls := 0
+			err := mfa.withMfa(func(serial, code string) error {
+				calls++
+				return tt.callErr
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("withMfa() calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("withMfa() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("withMfa() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// the account list of the organization is read again after this duration
+const accountCacheTTL = time.Hour
+
+// matchAccount finds the account with the given name ignoring the case. Names used by several accounts are reported as error.
+func matchAccount(accounts []AccountInfo, name string) (AccountInfo, error) {
+	matches := lo.Filter(accounts, func(account AccountInfo, _ int) bool {
+		return strings.EqualFold(account.Name, name)
+	})
+
+	switch len(matches) {
+	case 0:
+		return AccountInfo{}, accountNotFound(name, lo.Map(accounts, func(account AccountInfo, _ int) string {
+			return account.Name
+		}))
+	case 1:
+		return matches[0], nil
+	default:
+		ids := lo.Map(matches, func(account AccountInfo, _ int) string {
+			return fmt.Sprintf("%s (%s)", account.Name, account.Id)
+		})
+		return AccountInfo{}, errors.Errorf("the account name %q is ambiguous: %s; use the account id or the OU path instead", name, strings.Join(ids, ", "))
+	}
+}
+
+// findAccountIdByPath walks down the organizational units from the root, e.g. `workloads/prod/payments` is the account
+// `payments` in the OU `prod` below the OU `workloads`
+func (a *awsAbstraction) findAccountIdByPath(path string) (string, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	client := organizations.NewFromConfig(a.config)
+
+	roots, err := client.ListRoots(context.Background(), &organizations.ListRootsInput{})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list organization roots")
+	}
+	if len(roots.Roots) == 0 {
+		return "", errors.Errorf("the organization has no root")
+	}
+
+	parentId := aws.ToString(roots.Roots[0].Id)
+	for i, segment := range segments[:len(segments)-1] {
+		unit, err := findOrganizationalUnit(client, parentId, segment)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %q: %w", strings.Join(segments[:i+1], "/"), err)
+		}
+		parentId = aws.ToString(unit.Id)
+	}
+
+	var accounts []AccountInfo
+	paginator := organizations.NewListAccountsForParentPaginator(client, &organizations.ListAccountsForParentInput{
+		ParentId: aws.String(parentId),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to list accounts of %s", path)
+		}
+		for _, account := range page.Accounts {
+			accounts = append(accounts, AccountInfo{Id: aws.ToString(account.Id), Name: aws.ToString(account.Name)})
+		}
+	}
+
+	account, err := matchAccount(accounts, segments[len(segments)-1])
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	return account.Id, nil
+}
+
+func findOrganizationalUnit(client *organizations.Client, parentId, name string) (types.OrganizationalUnit, error) {
+	var names []string
+	paginator := organizations.NewListOrganizationalUnitsForParentPaginator(client, &organizations.ListOrganizationalUnitsForParentInput{
+		ParentId: aws.String(parentId),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return types.OrganizationalUnit{}, errors.Wrap(err, "failed to list organizational units")
+		}
+		for _, unit := range page.OrganizationalUnits {
+			if strings.EqualFold(aws.ToString(unit.Name), name) {
+				return unit, nil
+			}
+			names = append(names, aws.ToString(unit.Name))
+		}
+	}
+	return types.OrganizationalUnit{}, errors.Errorf("no organizational unit %q found (available: %s)", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchAccount(t *testing.T) {
+	accounts := []AccountInfo{
+		{Id: "111111111111", Name: "payments"},
+		{Id: "222222222222", Name: "Shared"},
+		{Id: "333333333333", Name: "shared"},
+	}
+
+	tests := []struct {
+		name    string
+		wantId  string
+		wantErr string
+	}{
+		{name: "Payments", wantId: "111111111111"},
+		{name: "shared", wantErr: "ambiguous"},
+		{name: "payment", wantErr: `did you mean "payments"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, err := matchAccount(accounts, tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("matchAccount() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if account.Id != tt.wantId {
+				t.Errorf("matchAccount() = %v, want %v", account.Id, tt.wantId)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/apex/log"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// ResolveRoleChain returns the role chain configured with the name in the profile or parses a list of
+// `account:role` hops (e.g. `hub:DeployRole,target:AppAdmin`)
+func ResolveRoleChain(profileName, value string) ([]config.RoleChainHop, error) {
+	if !strings.Contains(value, ":") {
+		profile, err := selectProfile(profileName)
+		if err != nil {
+			return nil, err
+		}
+		hops, found := profile.RoleChains[value]
+		if !found {
+			return nil, errors.Errorf("profile %q has no role chain %q", profile.Name, value)
+		}
+		if len(hops) == 0 {
+			return nil, errors.Errorf("role chain %q has no hops", value)
+		}
+		return hops, nil
+	}
+
+	return ParseRoleChain(value)
+}
+
+// ParseRoleChain parses a list of `account:role` hops. The account of the last hop may be empty to use the target account.
+func ParseRoleChain(value string) ([]config.RoleChainHop, error) {
+	var hops []config.RoleChainHop
+	for _, hop := range strings.Split(value, ",") {
+		account, role, found := strings.Cut(strings.TrimSpace(hop), ":")
+		if !found || role == "" {
+			return nil, errors.Errorf("invalid role chain hop %q; use <account>:<role>", hop)
+		}
+		hops = append(hops, config.RoleChainHop{Account: account, Role: role})
+	}
+
+	for _, hop := range hops[:len(hops)-1] {
+		if hop.Account == "" {
+			return nil, errors.Errorf("only the last hop of a role chain may omit the account")
+		}
+	}
+	return hops, nil
+}
+
+// AssumeRoleChain assumes the first role with the auth strategy and each further role with the credentials of the
+// previous one. An empty account in the last hop is replaced by the target account.
+func AssumeRoleChain(awsAbstraction IAws, hops []config.RoleChainHop, targetAccount string, mfa bool) (*AwsAccountAccess, error) {
+	hops = append([]config.RoleChainHop{}, hops...)
+	last := &hops[len(hops)-1]
+	switch {
+	case last.Account == "" && targetAccount == "":
+		return nil, errors.Errorf("the last hop of the role chain has no account and no target account is given")
+	case last.Account == "":
+		last.Account = targetAccount
+	case targetAccount != "" && !strings.EqualFold(last.Account, targetAccount):
+		return nil, errors.Errorf("the role chain ends in account %q, but the target account is %q", last.Account, targetAccount)
+	}
+
+	first := hops[0]
+	assume := lo.Ternary(mfa, awsAbstraction.AssumeRoleWithMfa, awsAbstraction.AssumeRole)
+	access, err := assume(first.Role, first.Account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role %q in %q: %w", first.Role, first.Account, err)
+	}
+
+	for _, hop := range hops[1:] {
+		accountId, err := awsAbstraction.FindAccountId(hop.Account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account id for account %q: %w", hop.Account, err)
+		}
+
+		if access, err = chainRole(access, awsAbstraction.Region(), awsAbstraction.Session(), accountId, hop); err != nil {
+			return nil, err
+		}
+	}
+	return access, nil
+}
+
+// maxChainedSessionDuration is the limit AWS enforces for sessions of roles assumed with the credentials of another role
+const maxChainedSessionDuration = time.Hour
+
+// chainRole assumes the role of the hop using the credentials of the previous hop. The options of the hop take
+// precedence over the session options.
+func chainRole(previous *AwsAccountAccess, region string, session SessionOptions, accountId string, hop config.RoleChainHop) (*AwsAccountAccess, error) {
+	cfg, err := CreateConfig(previous, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default config: %w", err)
+	}
+
+	session = chainedSession(session, hop)
+	input := &sts.AssumeRoleInput{
+		RoleArn: aws.String(roleArn(accountId, hop.Role)),
+	}
+	if hop.ExternalId != "" {
+		input.ExternalId = aws.String(hop.ExternalId)
+	}
+	session.apply(input)
+
+	log.Infof("Assuming role %s with the credentials of account %s", *input.RoleArn, previous.AccountId)
+	response, err := sts.NewFromConfig(cfg).AssumeRole(context.Background(), input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to assume role %s in account %s", hop.Role, accountId)
+	}
+
+	return &AwsAccountAccess{
+		AccountId:    accountId,
+		AccessKeyId:  *response.Credentials.AccessKeyId,
+		SecretKey:    *response.Credentials.SecretAccessKey,
+		SessionToken: *response.Credentials.SessionToken,
+		Expiration:   aws.ToTime(response.Credentials.Expiration),
+		SessionName:  *input.RoleSessionName,
+	}, nil
+}
+
+// chainedSession applies the options of the hop to the session options. The duration is shortened to the limit of
+// chained roles, as AWS rejects longer ones.
+func chainedSession(session SessionOptions, hop config.RoleChainHop) SessionOptions {
+	if hop.SessionDuration > 0 {
+		session.Duration = hop.SessionDuration
+	}
+	if session.Duration > maxChainedSessionDuration {
+		log.Debugf("shortening the session duration of role %s from %s to %s, the limit of chained roles", hop.Role, session.Duration, maxChainedSessionDuration)
+		session.Duration = maxChainedSessionDuration
+	}
+	if hop.SourceIdentity != "" {
+		session.SourceIdentity = hop.SourceIdentity
+	}
+	tags := maps.Clone(session.Tags)
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	maps.Copy(tags, hop.Tags)
+	session.Tags = tags
+	return session
+}
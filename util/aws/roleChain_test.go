@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/IronFE/iron.cli/util/config"
+)
+
+func TestParseRoleChain(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []config.RoleChainHop
+		wantErr bool
+	}{
+		{
+			value: "hub:DeployRole,target:AppAdmin",
+			want:  []config.RoleChainHop{{Account: "hub", Role: "DeployRole"}, {Account: "target", Role: "AppAdmin"}},
+		},
+		{
+			value: "hub:DeployRole, :AppAdmin",
+			want:  []config.RoleChainHop{{Account: "hub", Role: "DeployRole"}, {Role: "AppAdmin"}},
+		},
+		{value: ":DeployRole,target:AppAdmin", wantErr: true},
+		{value: "hub:DeployRole,target", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseRoleChain(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRoleChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) && !tt.wantErr {
+				t.Errorf("ParseRoleChain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainedSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		session  time.Duration
+		hop      time.Duration
+		expected time.Duration
+	}{
+		{name: "default", expected: 0},
+		{name: "session", session: 30 * time.Minute, expected: 30 * time.Minute},
+		{name: "long session", session: 8 * time.Hour, expected: time.Hour},
+		{name: "long hop", session: 30 * time.Minute, hop: 2 * time.Hour, expected: time.Hour},
+		{name: "short hop", session: 8 * time.Hour, hop: 15 * time.Minute, expected: 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := chainedSession(SessionOptions{Duration: tt.session}, config.RoleChainHop{Role: "AppAdmin", SessionDuration: tt.hop})
+			if session.Duration != tt.expected {
+				t.Errorf("chainedSession() duration = %s, want %s", session.Duration, tt.expected)
+			}
+		})
+	}
+}
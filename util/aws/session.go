@@ -0,0 +1,137 @@
+package aws
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"os/user"
+	"regexp"
+	"text/template"
+	"time"
+
+	"github.com/IronFE/iron.cli/util/config"
+	"github.com/IronFE/iron.cli/util/git"
+	"github.com/apex/log"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+const defaultSessionNameTemplate = "IronCLI@{{.Hostname}}"
+
+// session names are limited to 64 characters of this set
+var sessionNameInvalidChars = regexp.MustCompile(`[^\w+=,.@-]`)
+
+// SessionOptions configure the sessions of assumed roles
+type SessionOptions struct {
+	Duration time.Duration
+	// NameTemplate is a Go template for the role session name, see SessionTemplateData
+	NameTemplate string
+	// SourceIdentity is a Go template like the name template
+	SourceIdentity string
+	Tags           map[string]string
+	// Deployment is the name of the deployment the session is used for
+	Deployment string
+}
+
+// SessionTemplateData is available in the templates of the session name and source identity
+type SessionTemplateData struct {
+	User       string
+	GitUser    string
+	Hostname   string
+	Deployment string
+}
+
+// Merge returns the options with all empty fields set from the given defaults. Tags are merged.
+func (s SessionOptions) Merge(defaults config.Session) SessionOptions {
+	if s.Duration == 0 {
+		s.Duration = defaults.Duration
+	}
+	if s.NameTemplate == "" {
+		s.NameTemplate = defaults.NameTemplate
+	}
+	if s.SourceIdentity == "" {
+		s.SourceIdentity = defaults.SourceIdentity
+	}
+
+	tags := maps.Clone(defaults.Tags)
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	maps.Copy(tags, s.Tags)
+	s.Tags = tags
+	return s
+}
+
+// DurationOr returns the configured duration or the fallback if none is configured
+func (s SessionOptions) DurationOr(fallback time.Duration) time.Duration {
+	if s.Duration > 0 {
+		return s.Duration
+	}
+	return fallback
+}
+
+// RoleSessionName renders the name template
+func (s SessionOptions) RoleSessionName() string {
+	nameTemplate := s.NameTemplate
+	if nameTemplate == "" {
+		nameTemplate = defaultSessionNameTemplate
+	}
+
+	name := sessionNameInvalidChars.ReplaceAllString(s.render(nameTemplate), "-")
+	if len(name) < 2 {
+		name = "IronCLI"
+	}
+	return name[:min(len(name), 64)]
+}
+
+// apply sets the session options on the input of an STS AssumeRole call
+func (s SessionOptions) apply(input *sts.AssumeRoleInput) {
+	input.RoleSessionName = aws.String(s.RoleSessionName())
+	if s.Duration > 0 {
+		input.DurationSeconds = aws.Int32(int32(s.Duration.Seconds()))
+	}
+	if sourceIdentity := s.sourceIdentity(); sourceIdentity != "" {
+		input.SourceIdentity = aws.String(sourceIdentity)
+	}
+	for key, value := range s.Tags {
+		input.Tags = append(input.Tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+}
+
+// sourceIdentity renders the source identity template, if there is one
+func (s SessionOptions) sourceIdentity() string {
+	if s.SourceIdentity == "" {
+		return ""
+	}
+	return sessionNameInvalidChars.ReplaceAllString(s.render(s.SourceIdentity), "-")
+}
+
+func (s SessionOptions) render(text string) string {
+	parsed, err := template.New("session").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		log.WithError(err).Warnf("invalid session template %q", text)
+		return text
+	}
+
+	buffer := bytes.Buffer{}
+	if err = parsed.Execute(&buffer, s.templateData()); err != nil {
+		log.WithError(err).Warnf("failed to render session template %q", text)
+		return text
+	}
+	return buffer.String()
+}
+
+func (s SessionOptions) templateData() SessionTemplateData {
+	data := SessionTemplateData{Hostname: "Unknown", Deployment: s.Deployment}
+	if hostname, err := os.Hostname(); err == nil {
+		data.Hostname = hostname
+	}
+	if current, err := user.Current(); err == nil {
+		data.User = current.Username
+	}
+	if gitUser, err := git.UserEmail("."); err == nil {
+		data.GitUser = gitUser
+	}
+	return data
+}
@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IronFE/iron.cli/util/config"
+)
+
+func TestRoleSessionName(t *testing.T) {
+	tests := []struct {
+		name    string
+		session SessionOptions
+		want    string
+	}{
+		{name: "deployment", session: SessionOptions{NameTemplate: "iron-{{.Deployment}}", Deployment: "network"}, want: "iron-network"},
+		{name: "invalid characters", session: SessionOptions{NameTemplate: "{{.Deployment}}", Deployment: "my deployment/eu"}, want: "my-deployment-eu"},
+		{name: "too long", session: SessionOptions{NameTemplate: strings.Repeat("a", 70)}, want: strings.Repeat("a", 64)},
+		{name: "too short", session: SessionOptions{NameTemplate: "{{.Deployment}}"}, want: "IronCLI"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.RoleSessionName(); got != tt.want {
+				t.Errorf("RoleSessionName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionMerge(t *testing.T) {
+	session := SessionOptions{
+		NameTemplate: "{{.User}}",
+		Tags:         map[string]string{"team": "payments"},
+	}.Merge(config.Session{
+		Duration:     2 * time.Hour,
+		NameTemplate: "{{.Hostname}}",
+		Tags:         map[string]string{"team": "platform", "cost-center": "42"},
+	})
+
+	want := SessionOptions{
+		Duration:     2 * time.Hour,
+		NameTemplate: "{{.User}}",
+		Tags:         map[string]string{"team": "payments", "cost-center": "42"},
+	}
+	if !reflect.DeepEqual(session, want) {
+		t.Errorf("Merge() = %v, want %v", session, want)
+	}
+}
@@ -2,9 +2,12 @@ package aws
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/IronFE/iron.cli/util/config"
+	"github.com/samber/lo"
 )
 
 type AwsAccountAccess struct {
@@ -12,53 +15,94 @@ type AwsAccountAccess struct {
 	AccessKeyId  string
 	SecretKey    string
 	SessionToken string
+	Expiration   time.Time
+	// SessionName is the name of the role session if it was assumed via STS
+	SessionName string
+	// Region is the default region of the account if one is configured
+	Region string
 }
 
 type IAws interface {
 	FindAccountId(alias string) (string, error)
 	OrganizationInfo() (AwsOrganizationInfo, error)
+	// ListAccounts returns all accessible accounts including the available roles if the strategy knows them
+	ListAccounts() ([]AccountInfo, error)
 	AssumeRole(role, accountName string) (*AwsAccountAccess, error)
 	AssumeRoleWithMfa(role, accountName string) (*AwsAccountAccess, error)
 	SessionToken(duration time.Duration) (*AwsAccountAccess, error)
+	// SessionTokenRequiresMfa reports whether SessionToken asks for an MFA code
+	SessionTokenRequiresMfa() bool
+	Login(options LoginOptions) error
+	// Logout revokes the login and deletes all cached tokens and credentials
+	Logout() error
+	// LoginExpiration returns the expiration of the current login or a zero time if the strategy has no login
+	LoginExpiration() (time.Time, error)
 	Region() string
+	// Session returns the options for sessions of assumed roles
+	Session() SessionOptions
+}
+
+// StrategyFactory creates the auth strategy of a profile. Invalid configurations are reported as error.
+type StrategyFactory func(profile config.Profile, session SessionOptions) (IAws, error)
+
+var strategies = map[string]StrategyFactory{
+	"identityCenter": newIdentityCenterStrategyForProfile,
+	"iam":            newCredsStrategyForProfile,
+	"awsProfile":     newAwsProfileStrategy,
+	"webIdentity":    newWebIdentityStrategy,
+	"environment":    newEnvironmentStrategy,
+}
+
+// RegisterStrategy makes a strategy available for the `authStrategy` of profiles
+func RegisterStrategy(name string, factory StrategyFactory) {
+	strategies[name] = factory
+}
+
+func StrategyNames() []string {
+	names := lo.Keys(strategies)
+	slices.Sort(names)
+	return names
 }
 
 func NewAws(profileName string) (IAws, error) {
-	profileProvider := config.NewProfileProvider()
-	var selectedProfile config.Profile
-	var err error
-	if profileName != "" {
-		selectedProfile, err = profileProvider.Profile(profileName)
-		if err != nil {
-			return nil, fmt.Errorf("could not load profile %s: %w", profileName, err)
-		}
-	} else {
-		selectedProfile, err = profileProvider.DefaultProfile()
-		if err != nil {
-			return nil, fmt.Errorf("could not load default profile: %w", err)
-		}
+	return NewAwsWithSession(profileName, SessionOptions{})
+}
+
+// NewAwsWithSession creates the strategy of the profile. Empty session options are taken from the profile.
+func NewAwsWithSession(profileName string, session SessionOptions) (IAws, error) {
+	selectedProfile, err := selectProfile(profileName)
+	if err != nil {
+		return nil, err
 	}
+	session = session.Merge(selectedProfile.Session)
 
-	switch selectedProfile.AuthStrategy {
-	case "identityCenter":
-		idcRegion := selectedProfile.IdentityCenter.Region
-		if idcRegion == "" {
-			idcRegion = selectedProfile.DefaultRegion
-		}
+	factory, found := strategies[selectedProfile.AuthStrategy]
+	if !found {
+		return nil, fmt.Errorf("no strategy %q found; available strategies are %s", selectedProfile.AuthStrategy, strings.Join(StrategyNames(), ", "))
+	}
+
+	strategy, err := factory(selectedProfile, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s strategy of profile %q: %w", selectedProfile.AuthStrategy, selectedProfile.Name, err)
+	}
+
+	return newAliasingAws(newCachingAws(strategy, selectedProfile.Name), selectedProfile.Accounts), nil
+}
 
-		return NewIdentityCenterStrategy(
-			selectedProfile.IdentityCenter.StartUrl,
-			selectedProfile.IdentityCenter.DefaultRole,
-			selectedProfile.DefaultRegion,
-			idcRegion), nil
-	case "iam":
-		strategy, err := newCredsStrategyAws(selectedProfile.IAM.ProfileName, selectedProfile.DefaultRegion, selectedProfile.IAM.MfaSerial)
+// selectProfile returns the profile with the name or the default profile if no name is given
+func selectProfile(profileName string) (config.Profile, error) {
+	profileProvider := config.NewProfileProvider()
+	if profileName != "" {
+		profile, err := profileProvider.Profile(profileName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create iam strategy: %w", err)
+			return config.Profile{}, fmt.Errorf("could not load profile %s: %w", profileName, err)
 		}
-		return strategy, nil
-	default:
-		return nil, fmt.Errorf("no strategy %q found", selectedProfile.AuthStrategy)
+		return profile, nil
 	}
 
+	profile, err := profileProvider.DefaultProfile()
+	if err != nil {
+		return config.Profile{}, fmt.Errorf("could not load default profile: %w", err)
+	}
+	return profile, nil
 }
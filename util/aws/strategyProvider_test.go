@@ -0,0 +1,31 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/IronFE/iron.cli/util/config"
+)
+
+func TestStrategiesRejectInvalidProfiles(t *testing.T) {
+	t.Setenv("AWS_ROLE_ARN", "")
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "")
+
+	tests := []struct {
+		name    string
+		profile config.Profile
+	}{
+		{name: "identityCenter", profile: config.Profile{DefaultRegion: "eu-central-1"}},
+		{name: "identityCenter", profile: config.Profile{IdentityCenter: &config.IdentityCenter{StartUrl: "https://example.awsapps.com/start"}, DefaultRegion: "eu-central-1"}},
+		{name: "iam", profile: config.Profile{}},
+		{name: "awsProfile", profile: config.Profile{AwsProfile: &config.AwsProfile{}}},
+		{name: "webIdentity", profile: config.Profile{WebIdentity: &config.WebIdentity{TokenFile: "/tmp/token"}}},
+		{name: "webIdentity", profile: config.Profile{WebIdentity: &config.WebIdentity{RoleArn: "arn:aws:iam::123456789012:role/ci"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := strategies[tt.name](tt.profile, SessionOptions{}); err == nil {
+				t.Errorf("%s strategy accepted an invalid profile", tt.name)
+			}
+		})
+	}
+}
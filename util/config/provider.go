@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/apex/log"
 	"gopkg.in/yaml.v3"
@@ -14,14 +15,17 @@ type provider struct {
 }
 
 type IProvider interface {
+	Profiles() ([]Profile, error)
 	Profile(name string) (Profile, error)
 	DefaultProfile() (Profile, error)
 	Terraform() (TerraformConfig, error)
+	AccountGroups() (map[string][]string, error)
 }
 
 type configFile struct {
-	Profiles  []Profile       `yaml:"profiles"`
-	Terraform TerraformConfig `yaml:"terraform"`
+	Profiles      []Profile           `yaml:"profiles"`
+	Terraform     TerraformConfig     `yaml:"terraform"`
+	AccountGroups map[string][]string `yaml:"accountGroups"`
 }
 
 type Profile struct {
@@ -30,18 +34,82 @@ type Profile struct {
 	AuthStrategy   string          `yaml:"authStrategy"`
 	IdentityCenter *IdentityCenter `yaml:"identityCenter"`
 	IAM            *IAM            `yaml:"iam"`
+	AwsProfile     *AwsProfile     `yaml:"awsProfile"`
+	WebIdentity    *WebIdentity    `yaml:"webIdentity"`
 	DefaultRegion  string          `yaml:"defaultRegion"`
+	// Accounts maps short aliases to accounts of the organization
+	Accounts map[string]Account `yaml:"accounts"`
+	// RoleChains are named lists of roles, which are assumed one after another
+	RoleChains map[string][]RoleChainHop `yaml:"roleChains"`
+	Session    Session                   `yaml:"session"`
+}
+
+// Session configures the sessions of roles assumed via STS
+type Session struct {
+	Duration       time.Duration     `yaml:"duration"`
+	NameTemplate   string            `yaml:"nameTemplate"`
+	SourceIdentity string            `yaml:"sourceIdentity"`
+	Tags           map[string]string `yaml:"tags"`
+}
+
+type RoleChainHop struct {
+	Account string `yaml:"account"`
+	Role    string `yaml:"role"`
+	// the following options only apply to hops after the first one, which uses the auth strategy of the profile
+	ExternalId      string            `yaml:"externalId"`
+	SessionDuration time.Duration     `yaml:"sessionDuration"`
+	SourceIdentity  string            `yaml:"sourceIdentity"`
+	Tags            map[string]string `yaml:"tags"`
+}
+
+type Account struct {
+	// Name of the account in the organization; the alias is used if empty
+	Name string `yaml:"name"`
+	// Id pins the account, so no lookup is required
+	Id     string `yaml:"id"`
+	Role   string `yaml:"role"`
+	Region string `yaml:"region"`
 }
 
 type IdentityCenter struct {
 	StartUrl    string `yaml:"startUrl"`
 	DefaultRole string `yaml:"defaultRole"`
 	Region      string `yaml:"region"`
+	NoBrowser   bool   `yaml:"noBrowser"`
 }
 
 type IAM struct {
+	ProfileName string       `yaml:"profileName"`
+	MfaSerial   string       `yaml:"mfaSerial"`
+	MfaProvider *MfaProvider `yaml:"mfaProvider"`
+}
+
+// MfaProvider obtains MFA codes without asking the user. Either a command or a keyring entry must be set.
+type MfaProvider struct {
+	// Command prints the code, e.g. the CLI of a password manager
+	Command string `yaml:"command"`
+	// Keyring references a base32 TOTP seed stored in the OS keyring
+	Keyring *KeyringEntry `yaml:"keyring"`
+}
+
+type KeyringEntry struct {
+	Service string `yaml:"service"`
+	Account string `yaml:"account"`
+}
+
+// AwsProfile reuses a profile of the AWS CLI config, e.g. one logged in with `aws sso login`
+type AwsProfile struct {
 	ProfileName string `yaml:"profileName"`
-	MfaSerial   string `yaml:"mfaSerial"`
+}
+
+// WebIdentity exchanges an OIDC token (e.g. of GitHub Actions) for the credentials of a role
+type WebIdentity struct {
+	// RoleArn defaults to the environment variable AWS_ROLE_ARN
+	RoleArn string `yaml:"roleArn"`
+	// TokenFile defaults to the environment variable AWS_WEB_IDENTITY_TOKEN_FILE
+	TokenFile string `yaml:"tokenFile"`
+	// TokenEnv is the name of an environment variable containing the token
+	TokenEnv string `yaml:"tokenEnv"`
 }
 
 func NewProfileProvider() IProvider {
@@ -68,6 +136,13 @@ func (p *provider) DefaultProfile() (Profile, error) {
 	return defaultProfile, nil
 }
 
+func (p *provider) Profiles() ([]Profile, error) {
+	if err := p.initialize(); err != nil {
+		return nil, err
+	}
+	return p.data.Profiles, nil
+}
+
 func (p *provider) Profile(name string) (Profile, error) {
 	if err := p.initialize(); err != nil {
 		return Profile{}, err
@@ -89,6 +164,13 @@ func (p *provider) Terraform() (TerraformConfig, error) {
 	return p.data.Terraform, nil
 }
 
+func (p *provider) AccountGroups() (map[string][]string, error) {
+	if err := p.initialize(); err != nil {
+		return nil, err
+	}
+	return p.data.AccountGroups, nil
+}
+
 func (p *provider) initialize() error {
 	if p.data == nil {
 		cfg, err := p.readConfig()
@@ -115,13 +197,20 @@ func (p *provider) readConfig() (configFile, error) {
 }
 
 func (p *provider) configFilePath() string {
+	return FilePath()
+}
+
+// BaseDir returns the folder all configuration and cache files of the Iron-CLI are stored in
+func BaseDir() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		log.WithError(err).Warn("failed to get the home directory")
 		home = os.Getenv("HOME")
 	}
-	baseFolder := filepath.Join(home, ".iron-cli")
+	return filepath.Join(home, ".iron-cli")
+}
 
-	configFilePath := filepath.Join(baseFolder, "config.yaml")
-	return configFilePath
+// FilePath returns the path of the global config file
+func FilePath() string {
+	return filepath.Join(BaseDir(), "config.yaml")
 }
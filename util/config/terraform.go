@@ -1,9 +1,46 @@
 package config
 
+const (
+	EngineTerraform = "terraform"
+	EngineTofu      = "tofu"
+)
+
 type TerraformConfig struct {
-	Providers        []*Provider
-	Backend          Backend
+	Providers []*Provider
+	Backend   Backend
+	// Engine is either `terraform` (default) or `tofu` for OpenTofu
+	Engine           string `yaml:"engine"`
 	TerraformVersion string `yaml:"terraform_version"`
+	// Install configures how the Terraform version is installed
+	Install TerraformInstall `yaml:"install"`
+	// ReuseModules keeps the downloaded modules of a deployment between runs
+	ReuseModules bool `yaml:"reuseModules"`
+	// Encryption configures the state and plan encryption of OpenTofu
+	Encryption *Encryption `yaml:"encryption"`
+	// DependsOn lists the names of deployments which must be applied before this one
+	DependsOn []string `yaml:"dependsOn"`
+	// Inputs maps variable names to outputs of other deployments
+	Inputs map[string]Input `yaml:"inputs"`
+}
+
+type Input struct {
+	Deployment string `yaml:"deployment"`
+	Output     string `yaml:"output"`
+	// Account is the alias of the account the deployment is in. If empty, the account of the current deployment is used.
+	Account string `yaml:"account"`
+}
+
+type TerraformInstall struct {
+	// Offline only uses already installed versions
+	Offline bool `yaml:"offline"`
+	// Mirror is the URL of a mirror of releases.hashicorp.com
+	Mirror string `yaml:"mirror"`
+}
+
+type Encryption struct {
+	// KmsKeyId is the id or ARN of the KMS key used to encrypt the state and plans
+	KmsKeyId string `yaml:"kmsKeyId"`
+	Region   string `yaml:"region"`
 }
 
 type Provider struct {
@@ -18,11 +55,49 @@ type Backend struct {
 	Config map[string]string
 }
 
+// EngineName returns the configured engine or `terraform` if none is set
+func (c *TerraformConfig) EngineName() string {
+	if c.Engine == "" {
+		return EngineTerraform
+	}
+	return c.Engine
+}
+
 func (c *TerraformConfig) Merge(other TerraformConfig) {
+	if other.Engine != "" {
+		c.Engine = other.Engine
+	}
+
+	if other.ReuseModules {
+		c.ReuseModules = true
+	}
+
+	if other.Encryption != nil {
+		c.Encryption = other.Encryption
+	}
+
 	if other.TerraformVersion != "" {
 		c.TerraformVersion = other.TerraformVersion
 	}
 
+	if other.Install.Offline {
+		c.Install.Offline = true
+	}
+	if other.Install.Mirror != "" {
+		c.Install.Mirror = other.Install.Mirror
+	}
+
+	if other.DependsOn != nil {
+		c.DependsOn = other.DependsOn
+	}
+
+	for name, input := range other.Inputs {
+		if c.Inputs == nil {
+			c.Inputs = make(map[string]Input)
+		}
+		c.Inputs[name] = input
+	}
+
 	if other.Backend.Type != "" {
 		c.Backend.Type = other.Backend.Type
 		c.Backend.Config = other.Backend.Config
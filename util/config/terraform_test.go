@@ -24,6 +24,64 @@ func TestTerraformConfig_Merge(t *testing.T) {
 				TerraformVersion: "1.1.0",
 			},
 		},
+		{
+			name: "Merge Install",
+			base: TerraformConfig{
+				Install: TerraformInstall{Offline: true, Mirror: "https://mirror.example.com"},
+			},
+			other: TerraformConfig{
+				Install: TerraformInstall{Mirror: "https://releases.example.com"},
+			},
+			expected: TerraformConfig{
+				Install: TerraformInstall{Offline: true, Mirror: "https://releases.example.com"},
+			},
+		},
+		{
+			name: "Merge Engine",
+			base: TerraformConfig{
+				Engine:     EngineTerraform,
+				Encryption: &Encryption{KmsKeyId: "alias/state"},
+			},
+			other: TerraformConfig{
+				Engine: EngineTofu,
+			},
+			expected: TerraformConfig{
+				Engine:     EngineTofu,
+				Encryption: &Encryption{KmsKeyId: "alias/state"},
+			},
+		},
+		{
+			name: "Merge DependsOn",
+			base: TerraformConfig{
+				DependsOn: []string{"network"},
+			},
+			other: TerraformConfig{
+				DependsOn: []string{"data"},
+			},
+			expected: TerraformConfig{
+				DependsOn: []string{"data"},
+			},
+		},
+		{
+			name: "Merge Inputs",
+			base: TerraformConfig{
+				Inputs: map[string]Input{
+					"vpc_id":    {Deployment: "network", Output: "vpc_id"},
+					"subnet_id": {Deployment: "network", Output: "subnet_id"},
+				},
+			},
+			other: TerraformConfig{
+				Inputs: map[string]Input{
+					"vpc_id": {Deployment: "network", Output: "vpc_id", Account: "dev"},
+				},
+			},
+			expected: TerraformConfig{
+				Inputs: map[string]Input{
+					"vpc_id":    {Deployment: "network", Output: "vpc_id", Account: "dev"},
+					"subnet_id": {Deployment: "network", Output: "subnet_id"},
+				},
+			},
+		},
 		{
 			name: "Merge Backend",
 			base: TerraformConfig{
@@ -31,3 +31,11 @@ func OriginRemote(workDir string) (string, error) {
 
 	return "", fmt.Errorf("no origin branch found in repo %s", workDir)
 }
+
+func CurrentCommit(workDir string) (string, error) {
+	return util.Run(workDir, "git", "rev-parse", "HEAD")
+}
+
+func UserEmail(workDir string) (string, error) {
+	return util.Run(workDir, "git", "config", "user.email")
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// skippedFolders are generated by tools and change without a change of the sources
+var skippedFolders = []string{".terraform", ".git"}
+
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// HashFolder calculates a hash over the relative paths and contents of all files in the folder.
+// The .terraform and .git folders as well as the excluded paths are skipped.
+func HashFolder(root string, exclude ...string) (string, error) {
+	hash := sha256.New()
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+
+		if (entry.IsDir() && slices.Contains(skippedFolders, entry.Name())) || slices.Contains(exclude, path) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, _ = fmt.Fprintf(hash, "%s\x00%d\x00", filepath.ToSlash(relPath), info.Size())
+		_, err = io.Copy(hash, file)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash folder %s: %w", root, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,63 @@
+package util
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// all prefix writers share one lock, so lines of parallel writers are never interleaved
+var prefixWriterMutex sync.Mutex
+
+type PrefixWriter struct {
+	out    io.Writer
+	prefix string
+	buffer []byte
+}
+
+func NewPrefixWriter(out io.Writer, prefix string) *PrefixWriter {
+	return &PrefixWriter{
+		out:    out,
+		prefix: prefix,
+	}
+}
+
+func (w *PrefixWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+
+	for {
+		index := bytes.IndexByte(w.buffer, '\n')
+		if index < 0 {
+			break
+		}
+
+		if err := w.writeLine(w.buffer[:index+1]); err != nil {
+			return 0, err
+		}
+		w.buffer = w.buffer[index+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes any pending output which is not terminated by a new line
+func (w *PrefixWriter) Flush() error {
+	if len(w.buffer) == 0 {
+		return nil
+	}
+
+	err := w.writeLine(append(w.buffer, '\n'))
+	w.buffer = nil
+	return err
+}
+
+func (w *PrefixWriter) writeLine(line []byte) error {
+	prefixWriterMutex.Lock()
+	defer prefixWriterMutex.Unlock()
+
+	if _, err := io.WriteString(w.out, w.prefix); err != nil {
+		return err
+	}
+	_, err := w.out.Write(line)
+	return err
+}